@@ -4,8 +4,10 @@ go 1.25
 
 require (
 	github.com/alicebob/miniredis/v2 v2.36.1 // indirect
+	github.com/andybalholm/brotli v1.2.0
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/klauspost/compress v1.18.0
 	github.com/redis/go-redis/v9 v9.16.0 // indirect
 	github.com/yuin/gopher-lua v1.1.1 // indirect
 )
@@ -0,0 +1,296 @@
+package httpx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNotRefreshable 凭证不支持刷新
+var errNotRefreshable = errors.New("httpx: 凭证不支持刷新")
+
+// Authenticator 为请求添加鉴权信息，每次尝试发送前调用
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc 函数形式的 Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate 实现 Authenticator 接口
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// Refresher 可在收到 401 后刷新凭证的 Authenticator
+// 刷新成功后客户端会重新鉴权并重试一次
+type Refresher interface {
+	// Refresh 使 req 携带的凭证失效，下次 Authenticate 时获取新凭证
+	Refresh(req *http.Request) error
+}
+
+// BasicAuth HTTP Basic 鉴权
+func BasicAuth(username, password string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// BearerToken 使用固定令牌的 Bearer 鉴权
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKeyQuery 把 API Key 作为查询参数 name 附加到 URL 上
+func APIKeyQuery(name, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		query := req.URL.Query()
+		query.Set(name, key)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	})
+}
+
+// ==================== Bearer 令牌源 ====================
+
+// TokenSource 提供访问令牌
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Token 带过期时间的访问令牌
+type Token struct {
+	AccessToken string
+	// Expiry 过期时间，零值表示永不过期
+	Expiry time.Time
+}
+
+// tokenExpiryDelta 令牌在过期前多久视为失效，避免请求途中过期
+const tokenExpiryDelta = 10 * time.Second
+
+// RefreshingTokenSource 缓存令牌，临近过期或被标记失效时重新获取
+// 并发调用共享同一次获取（single-flight），每个调用方各自响应 ctx 取消
+type RefreshingTokenSource struct {
+	fetch    func(ctx context.Context) (*Token, error)
+	now      func() time.Time
+	mu       sync.Mutex
+	token    *Token
+	inflight *tokenCall
+}
+
+// tokenCall 一次进行中的令牌获取
+type tokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewRefreshingTokenSource 创建可刷新的令牌源，fetch 用于获取新令牌
+// fetch 不会因单个调用方取消而中断，需要自行设置超时
+func NewRefreshingTokenSource(fetch func(ctx context.Context) (*Token, error)) *RefreshingTokenSource {
+	return &RefreshingTokenSource{
+		fetch: fetch,
+		now:   time.Now,
+	}
+}
+
+// Token 返回有效的访问令牌，必要时重新获取
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	if s.valid() {
+		token := s.token.AccessToken
+		s.mu.Unlock()
+		return token, nil
+	}
+	call := s.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		s.inflight = call
+		// 发起者取消不应影响其他等待者
+		go s.refresh(context.WithoutCancel(ctx), call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-call.done:
+	}
+	if call.err != nil {
+		return "", call.err
+	}
+	return call.token.AccessToken, nil
+}
+
+// refresh 获取新令牌并通知所有等待者
+func (s *RefreshingTokenSource) refresh(ctx context.Context, call *tokenCall) {
+	token, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	call.token, call.err = token, err
+	if err == nil {
+		s.token = token
+	}
+	s.inflight = nil
+	s.mu.Unlock()
+
+	close(call.done)
+}
+
+// Invalidate 使指定令牌失效；缓存已被其他请求刷新时不做处理
+func (s *RefreshingTokenSource) Invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken == accessToken {
+		s.token = nil
+	}
+}
+
+// valid 判断缓存的令牌是否可用，调用方需持有锁
+func (s *RefreshingTokenSource) valid() bool {
+	if s.token == nil {
+		return false
+	}
+	return s.token.Expiry.IsZero() || s.now().Add(tokenExpiryDelta).Before(s.token.Expiry)
+}
+
+// bearerAuth 从令牌源获取令牌的 Bearer 鉴权
+type bearerAuth struct {
+	source TokenSource
+}
+
+// BearerAuth 使用令牌源的 Bearer 鉴权
+// 令牌源实现了 Invalidate(string) 时，收到 401 会刷新令牌并重试一次
+func BearerAuth(source TokenSource) Authenticator {
+	return &bearerAuth{source: source}
+}
+
+// Authenticate 实现 Authenticator 接口
+func (a *bearerAuth) Authenticate(req *http.Request) error {
+	token, err := a.source.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Refresh 实现 Refresher 接口
+func (a *bearerAuth) Refresh(req *http.Request) error {
+	invalidator, ok := a.source.(interface{ Invalidate(accessToken string) })
+	if !ok {
+		return errNotRefreshable
+	}
+	invalidator.Invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+	return nil
+}
+
+// ==================== HMAC 签名 ====================
+
+// HMACConfig HMAC 请求签名配置
+// 待签名串为 "方法\n路径及查询\n时间戳\n请求体SHA256十六进制"
+type HMACConfig struct {
+	KeyID  string
+	Secret []byte
+	// Hash 签名使用的哈希算法，默认 SHA-256
+	Hash func() hash.Hash
+	// Now 获取当前时间，默认 time.Now，便于测试
+	Now func() time.Time
+}
+
+// HMACAuth HMAC 请求签名，签名结果写入 X-Auth-Key、X-Auth-Timestamp 和 X-Auth-Signature
+func HMACAuth(config HMACConfig) Authenticator {
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return AuthenticatorFunc(func(req *http.Request) error {
+		bodyHash, err := hashBody(req)
+		if err != nil {
+			return err
+		}
+		timestamp := strconv.FormatInt(config.Now().Unix(), 10)
+
+		mac := hmac.New(config.Hash, config.Secret)
+		io.WriteString(mac, StringToSign(req.Method, req.URL.RequestURI(), timestamp, bodyHash))
+
+		req.Header.Set("X-Auth-Key", config.KeyID)
+		req.Header.Set("X-Auth-Timestamp", timestamp)
+		req.Header.Set("X-Auth-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		return nil
+	})
+}
+
+// StringToSign 生成 HMAC 待签名串，服务端可用它校验签名
+func StringToSign(method, requestURI, timestamp, bodyHash string) string {
+	return strings.Join([]string{method, requestURI, timestamp, bodyHash}, "\n")
+}
+
+// hashBody 计算请求体的 SHA-256，计算后重置请求体
+func hashBody(req *http.Request) (string, error) {
+	sum := sha256.New()
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return "", errors.New("httpx: HMAC 签名需要可重放的请求体")
+		}
+		err := readBody(req, func(body io.Reader) error {
+			_, err := io.Copy(sum, body)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// ==================== 鉴权中间件 ====================
+
+// authenticate 内置鉴权中间件，位于重试之内，每次尝试都重新鉴权
+// 收到 401 且支持刷新时，刷新凭证后重试一次
+func authenticate(auth Authenticator) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := auth.Authenticate(req); err != nil {
+				return nil, err
+			}
+			resp, err := next(req)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+
+			refresher, ok := auth.(Refresher)
+			if !ok || !isReplayable(req) || refresher.Refresh(req) != nil {
+				return resp, nil
+			}
+			drainBody(resp)
+
+			retryReq, err := rewindRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			if err := auth.Authenticate(retryReq); err != nil {
+				return nil, err
+			}
+			return next(retryReq)
+		}
+	}
+}
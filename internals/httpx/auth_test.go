@@ -0,0 +1,267 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 内置鉴权方式测试 ====================
+
+func TestAuthenticators(t *testing.T) {
+	tests := []struct {
+		name  string
+		auth  Authenticator
+		check func(t *testing.T, r *http.Request)
+	}{
+		{
+			name: "Basic",
+			auth: BasicAuth("alice", "secret"),
+			check: func(t *testing.T, r *http.Request) {
+				user, pass, ok := r.BasicAuth()
+				if !ok || user != "alice" || pass != "secret" {
+					t.Errorf("BasicAuth = %q %q %v", user, pass, ok)
+				}
+			},
+		},
+		{
+			name: "固定Bearer",
+			auth: BearerToken("abc"),
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer abc" {
+					t.Errorf("Authorization = %q", got)
+				}
+			},
+		},
+		{
+			name: "查询参数API Key",
+			auth: APIKeyQuery("api_key", "k&1"),
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.URL.Query().Get("api_key"); got != "k&1" {
+					t.Errorf("api_key = %q", got)
+				}
+				if got := r.URL.Query().Get("page"); got != "2" {
+					t.Errorf("原有查询参数丢失: page = %q", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				w.WriteHeader(http.StatusOK)
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, Auth: tt.auth})
+			resp, err := client.Get(context.Background(), "/test?page=2", nil)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+// ==================== HMAC 签名测试 ====================
+
+func TestHMACAuth(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1700000000, 0)
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"test"}` {
+			t.Errorf("签名后请求体 = %q", body)
+		}
+
+		bodyHash := sha256.Sum256(body)
+		mac := hmac.New(sha256.New, secret)
+		io.WriteString(mac, StringToSign(r.Method, r.URL.RequestURI(), r.Header.Get("X-Auth-Timestamp"), hex.EncodeToString(bodyHash[:])))
+		want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+		if got := r.Header.Get("X-Auth-Signature"); got != want {
+			t.Errorf("签名 = %q，期望 %q", got, want)
+		}
+		if r.Header.Get("X-Auth-Key") != "key-1" || r.Header.Get("X-Auth-Timestamp") != "1700000000" {
+			t.Errorf("X-Auth-Key = %q, X-Auth-Timestamp = %q", r.Header.Get("X-Auth-Key"), r.Header.Get("X-Auth-Timestamp"))
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Auth: HMACAuth(HMACConfig{
+			KeyID:  "key-1",
+			Secret: secret,
+			Now:    func() time.Time { return now },
+		}),
+	})
+
+	resp, err := client.Post(context.Background(), "/orders?id=1", map[string]string{"name": "test"}, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestHMACAuth_SeekableBody(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 计算签名后请求体仍需完整发送
+		if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+			t.Errorf("请求体 = %q，期望 payload", body)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Auth:    HMACAuth(HMACConfig{KeyID: "k", Secret: []byte("s")}),
+	})
+
+	body := struct{ io.ReadSeeker }{strings.NewReader("payload")}
+	resp, err := client.Request(context.Background(), http.MethodPost, "/", body, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestHMACAuth_NonReplayableBody(t *testing.T) {
+	client := NewClient(Config{
+		BaseURL: "http://127.0.0.1:0",
+		Auth:    HMACAuth(HMACConfig{KeyID: "k", Secret: []byte("s")}),
+	})
+
+	body := io.NopCloser(bytes.NewBufferString("data"))
+	if _, err := client.Request(context.Background(), http.MethodPost, "/", body, nil); err == nil {
+		t.Error("不可重放的请求体期望返回错误")
+	}
+}
+
+// ==================== 可刷新令牌测试 ====================
+
+func TestRefreshingTokenSource(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var fetches int
+	source := NewRefreshingTokenSource(func(ctx context.Context) (*Token, error) {
+		fetches++
+		return &Token{AccessToken: fmt.Sprintf("t%d", fetches), Expiry: now.Add(time.Hour)}, nil
+	})
+	source.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if got, _ := source.Token(ctx); got != "t1" {
+		t.Errorf("Token() = %q，期望 t1", got)
+	}
+	if got, _ := source.Token(ctx); got != "t1" {
+		t.Errorf("未过期时 Token() = %q，期望缓存的 t1", got)
+	}
+
+	// 过期前提前刷新
+	now = now.Add(time.Hour - tokenExpiryDelta)
+	if got, _ := source.Token(ctx); got != "t2" {
+		t.Errorf("临近过期时 Token() = %q，期望 t2", got)
+	}
+
+	// 使旧令牌失效不影响新令牌
+	source.Invalidate("t1")
+	if got, _ := source.Token(ctx); got != "t2" {
+		t.Errorf("Invalidate 旧令牌后 Token() = %q，期望 t2", got)
+	}
+	source.Invalidate("t2")
+	if got, _ := source.Token(ctx); got != "t3" {
+		t.Errorf("Invalidate 后 Token() = %q，期望 t3", got)
+	}
+}
+
+func TestClient_RefreshOn401(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("t2")
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"a":1}` {
+			t.Errorf("刷新重试的请求体 = %q", body)
+		}
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	var mu sync.Mutex
+	var fetches int
+	source := NewRefreshingTokenSource(func(ctx context.Context) (*Token, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		return &Token{AccessToken: fmt.Sprintf("t%d", fetches)}, nil
+	})
+
+	client := NewClient(Config{BaseURL: server.URL, Auth: BearerAuth(source)})
+
+	resp, err := client.Post(context.Background(), "/test", map[string]int{"a": 1}, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("期望状态码 200，实际 %d", resp.StatusCode)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("期望请求 2 次，实际 %d 次", got)
+	}
+
+	// 刷新后仍然 401 时只重试一次，不会循环
+	valid.Store("never")
+	attempts.Store(0)
+	resp, err = client.Post(context.Background(), "/test", map[string]int{"a": 1}, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || attempts.Load() != 2 {
+		t.Errorf("状态码 %d、请求 %d 次，期望 401、2 次", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestClient_401WithoutRefresher(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Auth: BearerToken("static")})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Status, "401") || attempts.Load() != 1 {
+		t.Errorf("状态 %s、请求 %d 次，期望 401、1 次", resp.Status, attempts.Load())
+	}
+}
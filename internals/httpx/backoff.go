@@ -0,0 +1,121 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backoff 退避策略，返回第 attempt 次重试前的等待时间
+// attempt 从 0 开始计数，prev 为上一次实际等待的时间（首次为 0）
+type Backoff func(attempt int, prev time.Duration) time.Duration
+
+// ConstantBackoff 固定间隔退避
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff 指数退避，等待 base * 2^attempt
+func ExponentialBackoff(base time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return expDelay(base, attempt)
+	}
+}
+
+// FullJitterBackoff 全抖动指数退避，在 [0, base * 2^attempt) 内随机等待
+func FullJitterBackoff(base time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return randDuration(0, expDelay(base, attempt))
+	}
+}
+
+// DecorrelatedJitterBackoff 去相关抖动退避，在 [base, prev * 3) 内随机等待
+func DecorrelatedJitterBackoff(base time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+		upper := prev * 3
+		if upper < prev {
+			upper = math.MaxInt64
+		}
+		return randDuration(base, upper)
+	}
+}
+
+// expDelay 计算 base * 2^attempt，溢出时返回最大值
+func expDelay(base time.Duration, attempt int) time.Duration {
+	if attempt >= 62 || base > math.MaxInt64>>attempt {
+		return math.MaxInt64
+	}
+	return base << attempt
+}
+
+// randDuration 返回 [lo, hi) 内的随机时长
+func randDuration(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + rand.N(hi-lo)
+}
+
+// nextDelay 计算下一次重试前的等待时间，返回 false 表示不应再重试
+// 服务端的 Retry-After 优先于退避策略
+func (c *Client) nextDelay(attempt int, prev time.Duration, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// 要求等待的时间超过上限，直接把响应交给调用方处理
+			if after > c.maxDelay {
+				return 0, false
+			}
+			return after, true
+		}
+	}
+	return min(c.backoff(attempt, prev), c.maxDelay), true
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和 HTTP-date 两种格式
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	// 超出 int64 的秒数 ParseInt 返回 ErrRange 和最大值
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		if seconds < 0 {
+			return 0, false
+		}
+		// 超出 time.Duration 范围时按最大值处理，避免溢出为负数后立即重试
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+// sleepContext 等待指定时间，ctx 取消时立即返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
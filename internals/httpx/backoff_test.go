@@ -0,0 +1,213 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 退避策略测试 ====================
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10 * time.Millisecond)
+
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond}
+	for attempt, w := range want {
+		if got := backoff(attempt, 0); got != w {
+			t.Errorf("attempt %d: got %v, want %v", attempt, got, w)
+		}
+	}
+
+	// 次数过大时不能溢出为负数
+	if got := backoff(100, 0); got <= 0 {
+		t.Errorf("attempt 100: got %v, want 正数", got)
+	}
+}
+
+func TestJitterBackoff(t *testing.T) {
+	base := 10 * time.Millisecond
+
+	t.Run("全抖动", func(t *testing.T) {
+		backoff := FullJitterBackoff(base)
+		for attempt := 0; attempt < 5; attempt++ {
+			upper := expDelay(base, attempt)
+			for i := 0; i < 100; i++ {
+				if got := backoff(attempt, 0); got < 0 || got >= upper {
+					t.Fatalf("attempt %d: got %v, want [0, %v)", attempt, got, upper)
+				}
+			}
+		}
+	})
+
+	t.Run("去相关抖动", func(t *testing.T) {
+		backoff := DecorrelatedJitterBackoff(base)
+		prev := time.Duration(0)
+		for attempt := 0; attempt < 10; attempt++ {
+			lower, upper := base, max(prev, base)*3
+			got := backoff(attempt, prev)
+			if got < lower || got >= upper {
+				t.Fatalf("attempt %d: got %v, want [%v, %v)", attempt, got, lower, upper)
+			}
+			prev = got
+		}
+	})
+}
+
+// ==================== Retry-After 解析测试 ====================
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "秒数", value: "3", want: 3 * time.Second, wantOK: true},
+		{name: "带空格的秒数", value: " 5 ", want: 5 * time.Second, wantOK: true},
+		{name: "零秒", value: "0", want: 0, wantOK: true},
+		{name: "HTTP日期", value: now.Add(10 * time.Second).Format(http.TimeFormat), want: 10 * time.Second, wantOK: true},
+		{name: "过去的HTTP日期", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{name: "空值", value: "", wantOK: false},
+		{name: "负数", value: "-1", wantOK: false},
+		{name: "超出Duration范围", value: "9300000000", want: math.MaxInt64, wantOK: true},
+		{name: "超出int64范围", value: "99999999999999999999", want: math.MaxInt64, wantOK: true},
+		{name: "无效值", value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, %v，期望 %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// ==================== 重试等待测试 ====================
+
+func TestClient_RetryAfter(t *testing.T) {
+	t.Run("遵循Retry-After", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		defer server.Close()
+
+		client := NewClient(Config{
+			BaseURL:    server.URL,
+			RetryDelay: time.Millisecond,
+		})
+
+		start := time.Now()
+		resp, err := client.Get(context.Background(), "/test", nil)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("期望状态码 200，实际 %d", resp.StatusCode)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("等待了 %v，期望至少 1s", elapsed)
+		}
+	})
+
+	// 超出 time.Duration 范围的秒数不能溢出为负数而立即重试
+	for _, value := range []string{"3600", "9300000000", "99999999999999999999"} {
+		t.Run("Retry-After超过上限不重试/"+value, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.Header().Set("Retry-After", value)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			defer server.Close()
+
+			client := NewClient(Config{
+				BaseURL:       server.URL,
+				MaxRetries:    3,
+				MaxRetryDelay: time.Second,
+			})
+
+			resp, err := client.Get(context.Background(), "/test", nil)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("期望状态码 503，实际 %d", resp.StatusCode)
+			}
+			if got := attempts.Load(); got != 1 {
+				t.Errorf("期望请求 1 次，实际 %d 次", got)
+			}
+		})
+	}
+}
+
+func TestClient_BackoffCappedByMaxDelay(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:       server.URL,
+		MaxRetries:    3,
+		Backoff:       ExponentialBackoff(time.Hour),
+		MaxRetryDelay: 5 * time.Millisecond,
+	})
+
+	start := time.Now()
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := attempts.Load(); got != 4 {
+		t.Errorf("期望请求 4 次，实际 %d 次", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("等待了 %v，退避应被 MaxRetryDelay 截断", elapsed)
+	}
+}
+
+func TestClient_RetryWaitCancelled(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		RetryDelay: 10 * time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.Get(ctx, "/test", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望 context.Canceled，实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("取消后等待了 %v，应立即返回", elapsed)
+	}
+}
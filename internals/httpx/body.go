@@ -0,0 +1,111 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrBodyNotReplayable 请求体是不可重放的流，无法安全地重试
+var ErrBodyNotReplayable = errors.New("httpx: 请求体不可重放，无法重试")
+
+// setGetBody 为可 Seek 的请求体补充 GetBody
+// bytes.Reader、bytes.Buffer、strings.Reader 已由 http.NewRequest 处理
+func setGetBody(req *http.Request, body io.Reader) error {
+	if req.GetBody != nil {
+		return nil
+	}
+	if b, ok := body.(*reopenableBody); ok {
+		req.GetBody = b.reopen
+		return nil
+	}
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		return nil
+	}
+
+	// 记录起始位置，重放时回到这里而不是文件开头
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(seeker), nil
+	}
+	return nil
+}
+
+// detachCloser 把可 Seek 且可关闭的请求体（如 *os.File）包装为不可关闭的 reader
+// transport 每次尝试后都会关闭请求体，文件被关闭后重试时无法 Seek；返回的 Closer 由调用方在所有尝试结束后关闭
+func detachCloser(body io.Reader) (io.Reader, io.Closer) {
+	rsc, ok := body.(io.ReadSeekCloser)
+	if !ok {
+		return body, nil
+	}
+	return io.NopCloser(rsc), rsc
+}
+
+// readBody 读取尚未发送的请求体（用于签名、日志等），读取后通过 GetBody 换上新的请求体
+// 直接读取 req.Body 而不是另外调用 GetBody，multipart、流式压缩等请求体只需重新生成一次；
+// 基于 Seek 的 GetBody 与 req.Body 共享读取位置，因此读完后才能获取新的请求体；read 出错时同样换上新的请求体
+func readBody(req *http.Request, read func(io.Reader) error) error {
+	if req.GetBody == nil {
+		return ErrBodyNotReplayable
+	}
+	err := read(req.Body)
+	req.Body.Close()
+	body, getErr := req.GetBody()
+	if getErr != nil {
+		return getErr
+	}
+	req.Body = body
+	return err
+}
+
+// reopenableBody 可以重新生成的请求体，如按文件路径流式生成的 multipart
+type reopenableBody struct {
+	io.ReadCloser
+	reopen func() (io.ReadCloser, error)
+}
+
+// isReplayable 判断请求体能否在重试时重新发送
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest 复制请求并重置请求体，供下一次尝试使用
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, ErrBodyNotReplayable
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
+
+// cancelBody 关闭时取消请求 context 的响应体
+// 用于请求在独立的 context 中发出、响应体需要在返回后继续读取的场景
+type cancelBody struct {
+	io.Reader
+	body   io.Closer
+	cancel context.CancelFunc
+}
+
+// Close 关闭响应体并取消 context
+func (b *cancelBody) Close() error {
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
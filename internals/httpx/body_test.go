@@ -0,0 +1,261 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 请求体重放测试 ====================
+
+func TestClient_RetryReplaysBody(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(c *Client) (*http.Response, error)
+		want string
+	}{
+		{
+			name: "POST重试发送完整JSON",
+			send: func(c *Client) (*http.Response, error) {
+				return c.Post(context.Background(), "/test", map[string]string{"name": "test"}, nil)
+			},
+			want: `{"name":"test"}`,
+		},
+		{
+			name: "PUT重试发送完整JSON",
+			send: func(c *Client) (*http.Response, error) {
+				return c.Put(context.Background(), "/test", map[string]int{"id": 1}, nil)
+			},
+			want: `{"id":1}`,
+		},
+		{
+			name: "可Seek请求体从起始位置重放",
+			send: func(c *Client) (*http.Response, error) {
+				r := strings.NewReader("skip:payload")
+				r.Seek(5, io.SeekStart)
+				// 包一层隐藏具体类型，避免 http.NewRequest 自动设置 GetBody
+				body := struct{ io.ReadSeeker }{r}
+				return c.Request(context.Background(), http.MethodPost, "/test", body, nil)
+			},
+			want: "payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var bodies []string
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				bodies = append(bodies, string(data))
+				n := len(bodies)
+				mu.Unlock()
+
+				if n < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			defer server.Close()
+
+			client := NewClient(Config{
+				BaseURL:    server.URL,
+				MaxRetries: 3,
+				RetryDelay: time.Millisecond,
+			})
+
+			resp, err := tt.send(client)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+
+			if len(bodies) != 3 {
+				t.Fatalf("期望请求 3 次，实际 %d 次", len(bodies))
+			}
+			for i, got := range bodies {
+				if got != tt.want {
+					t.Errorf("第 %d 次请求体 = %q，期望 %q", i+1, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_RetryFileBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(data))
+		n := len(bodies)
+		mu.Unlock()
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer server.Close()
+
+	file, err := os.CreateTemp(t.TempDir(), "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("file payload")
+	file.Seek(0, io.SeekStart)
+
+	client := NewClient(Config{BaseURL: server.URL, MaxRetries: 3, RetryDelay: time.Millisecond})
+	resp, err := client.Request(context.Background(), http.MethodPost, "/upload", file, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 3 {
+		t.Fatalf("期望请求 3 次，实际 %d 次", len(bodies))
+	}
+	for i, got := range bodies {
+		if got != "file payload" {
+			t.Errorf("第 %d 次请求体 = %q", i+1, got)
+		}
+	}
+	// 所有尝试结束后文件被关闭
+	if _, err := file.Seek(0, io.SeekStart); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("文件未关闭: %v", err)
+	}
+}
+
+func TestClient_RetryNonReplayableBody(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+	})
+
+	// io.Pipe 是典型的不可重放流
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("streaming"))
+		pw.Close()
+	}()
+
+	_, err := client.Request(context.Background(), http.MethodPost, "/upload", pr, nil)
+	if !errors.Is(err, ErrBodyNotReplayable) {
+		t.Fatalf("期望 ErrBodyNotReplayable，实际 %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("期望请求 1 次，实际 %d 次", got)
+	}
+}
+
+func TestClient_NonReplayableBodySucceeds(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+
+	// 不需要重试时流式请求体可以正常发送
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("streaming"))
+		pw.Close()
+	}()
+
+	resp, err := client.Request(context.Background(), http.MethodPost, "/upload", pr, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	data, _ := ParseRawResponse(resp)
+	if string(data) != "streaming" {
+		t.Errorf("响应 = %q，期望 %q", data, "streaming")
+	}
+}
+
+// ==================== 读取请求体测试 ====================
+
+func TestReadBody(t *testing.T) {
+	const content = "skip:payload"
+	errRead := errors.New("读取失败")
+
+	tests := []struct {
+		name    string
+		body    func() io.Reader
+		read    func(body io.Reader) error
+		want    string
+		wantErr error
+	}{
+		{
+			name: "长度已知的请求体",
+			body: func() io.Reader { return strings.NewReader("payload") },
+			read: func(body io.Reader) error { _, err := io.ReadAll(body); return err },
+			want: "payload",
+		},
+		{
+			name: "可Seek请求体从起始位置重新发送",
+			body: func() io.Reader {
+				r := strings.NewReader(content)
+				r.Seek(5, io.SeekStart)
+				return struct{ io.ReadSeeker }{r}
+			},
+			read: func(body io.Reader) error { _, err := io.ReadAll(body); return err },
+			want: "payload",
+		},
+		{
+			name:    "读取出错时仍可完整发送",
+			body:    func() io.Reader { return strings.NewReader("payload") },
+			read:    func(body io.Reader) error { body.Read(make([]byte, 3)); return errRead },
+			want:    "payload",
+			wantErr: errRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body()
+			req, _ := http.NewRequest(http.MethodPost, "http://example.com", body)
+			if err := setGetBody(req, body); err != nil {
+				t.Fatalf("setGetBody 失败: %v", err)
+			}
+			var calls int
+			getBody := req.GetBody
+			req.GetBody = func() (io.ReadCloser, error) {
+				calls++
+				return getBody()
+			}
+
+			if err := readBody(req, tt.read); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readBody() error = %v，期望 %v", err, tt.wantErr)
+			}
+			// 读取的是原请求体，只需重新获取一次
+			if calls != 1 {
+				t.Errorf("GetBody 调用 %d 次，期望 1", calls)
+			}
+			if data, _ := io.ReadAll(req.Body); string(data) != tt.want {
+				t.Errorf("读取后的请求体 = %q，期望 %q", data, tt.want)
+			}
+		})
+	}
+}
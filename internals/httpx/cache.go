@@ -0,0 +1,438 @@
+package httpx
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStorage 缓存存储，值为序列化后的响应
+// 存储出错时按未命中处理，请求照常发送
+type CacheStorage interface {
+	// Get 返回 key 对应的值，不存在时 ok 为 false
+	Get(key string) (value []byte, ok bool, err error)
+	// Set 保存值，ttl 为 0 表示不过期
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete 删除值
+	Delete(key string) error
+}
+
+// CacheConfig 响应缓存配置
+// 缓存为客户端私有缓存：遵循 Cache-Control、Expires、ETag 和 Last-Modified，
+// 新鲜的响应直接从缓存返回，过期的响应通过 If-None-Match / If-Modified-Since 协商
+type CacheConfig struct {
+	// Storage 缓存存储，默认为容量 1000 的内存 LRU
+	Storage CacheStorage
+	// MaxBodySize 可缓存的最大响应体，默认 1MB，超过时不缓存
+	MaxBodySize int64
+	// RetainStale 带校验信息的响应过期后继续保留用于协商的时间，默认 24 小时
+	RetainStale time.Duration
+}
+
+// defaultCacheSize 默认内存缓存容量
+const defaultCacheSize = 1000
+
+// heuristicMaxLifetime 根据 Last-Modified 启发式计算的新鲜期上限
+const heuristicMaxLifetime = 24 * time.Hour
+
+// cacheableStatus 默认可缓存的状态码（RFC 9110 15.1）
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// cacheEntry 缓存的响应
+type cacheEntry struct {
+	StatusCode   int               `json:"status"`
+	Header       http.Header       `json:"header"`
+	Body         []byte            `json:"body"`
+	RequestTime  time.Time         `json:"request_time"`
+	ResponseTime time.Time         `json:"response_time"`
+	Vary         map[string]string `json:"vary,omitempty"` // Vary 中列出的请求 header 的值
+}
+
+// responseCache 缓存中间件
+type responseCache struct {
+	config CacheConfig
+	now    func() time.Time
+}
+
+// newResponseCache 创建缓存中间件并填充默认配置
+func newResponseCache(config CacheConfig) *responseCache {
+	if config.Storage == nil {
+		config.Storage = NewMemoryCache(defaultCacheSize)
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.RetainStale <= 0 {
+		config.RetainStale = 24 * time.Hour
+	}
+	return &responseCache{config: config, now: time.Now}
+}
+
+// middleware 缓存中间件，位于重试之外，命中时不会发出请求
+// 只缓存 GET 请求；其他方法成功后删除同一 URL 的缓存
+func (rc *responseCache) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		key := req.URL.String()
+		if req.Method != http.MethodGet {
+			resp, err := next(req)
+			if err == nil && req.Method != http.MethodHead && resp.StatusCode < http.StatusBadRequest {
+				rc.config.Storage.Delete(key)
+			}
+			return resp, err
+		}
+
+		// 调用方自行协商或请求部分内容时不使用缓存
+		reqCC := parseCacheControl(req.Header.Get("Cache-Control"))
+		if reqCC.has("no-store") || req.Header.Get("Range") != "" ||
+			req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+			return next(req)
+		}
+
+		entry := rc.load(key, req)
+		if entry != nil && !reqCC.has("no-cache") {
+			age := entry.age(rc.now())
+			if entry.fresh(age, reqCC) {
+				return entry.response(req, age), nil
+			}
+		}
+
+		outReq := req
+		if entry != nil && entry.hasValidators() {
+			outReq = req.Clone(req.Context())
+			if etag := entry.Header.Get("ETag"); etag != "" {
+				outReq.Header.Set("If-None-Match", etag)
+			}
+			if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+				outReq.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+
+		requestTime := rc.now()
+		resp, err := next(outReq)
+		if err != nil {
+			return nil, err
+		}
+		responseTime := rc.now()
+
+		if resp.StatusCode == http.StatusNotModified && outReq != req {
+			drainBody(resp)
+			entry.revalidated(resp.Header, requestTime, responseTime)
+			rc.save(key, entry)
+			return entry.response(req, entry.age(responseTime)), nil
+		}
+		return rc.store(key, req, resp, requestTime, responseTime), nil
+	}
+}
+
+// load 读取缓存，Vary 不匹配或解析失败时返回 nil
+func (rc *responseCache) load(key string, req *http.Request) *cacheEntry {
+	data, ok, err := rc.config.Storage.Get(key)
+	if err != nil || !ok {
+		return nil
+	}
+	var entry cacheEntry
+	if json.Unmarshal(data, &entry) != nil {
+		return nil
+	}
+	for name, value := range entry.Vary {
+		if req.Header.Get(name) != value {
+			return nil
+		}
+	}
+	return &entry
+}
+
+// save 保存缓存，没有校验信息的响应过期后即删除
+func (rc *responseCache) save(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	ttl := max(entry.lifetime()-entry.age(entry.ResponseTime), 0)
+	if entry.hasValidators() {
+		ttl += rc.config.RetainStale
+	}
+	if ttl <= 0 {
+		return
+	}
+	rc.config.Storage.Set(key, data, ttl)
+}
+
+// store 读取可缓存的响应体并保存，返回的响应可以照常读取
+func (rc *responseCache) store(key string, req *http.Request, resp *http.Response, requestTime, responseTime time.Time) *http.Response {
+	respCC := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if !cacheableStatus[resp.StatusCode] || respCC.has("no-store") {
+		return resp
+	}
+
+	entry := &cacheEntry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+	// 既没有新鲜期也没有校验信息的响应缓存后无法使用
+	if !respCC.has("max-age") && resp.Header.Get("Expires") == "" && !entry.hasValidators() {
+		return resp
+	}
+	for _, field := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(field, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return resp
+			}
+			// 保存的响应体已经解码时，不同的 Accept-Encoding 得到的是同一内容
+			if name == "" || (strings.EqualFold(name, "Accept-Encoding") && resp.Header.Get("Content-Encoding") == "") {
+				continue
+			}
+			if entry.Vary == nil {
+				entry.Vary = make(map[string]string)
+			}
+			entry.Vary[http.CanonicalHeaderKey(name)] = req.Header.Get(name)
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, rc.config.MaxBodySize+1))
+	if err != nil || int64(len(body)) > rc.config.MaxBodySize {
+		// 不缓存，已读取的部分放回响应体
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Body = body
+	rc.save(key, entry)
+	return resp
+}
+
+// date 响应的 Date，缺失或无效时使用收到响应的时间
+func (e *cacheEntry) date() time.Time {
+	if t, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return t
+	}
+	return e.ResponseTime
+}
+
+// age 计算当前的 Age（RFC 9111 4.2.3）
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	apparentAge := max(e.ResponseTime.Sub(e.date()), 0)
+	var ageValue time.Duration
+	if secs, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil {
+		ageValue = time.Duration(secs) * time.Second
+	}
+	correctedAge := max(apparentAge, ageValue+e.ResponseTime.Sub(e.RequestTime))
+	return correctedAge + now.Sub(e.ResponseTime)
+}
+
+// lifetime 计算新鲜期（RFC 9111 4.2.1）
+// 优先使用 max-age，其次 Expires，最后按 Last-Modified 启发式取距今的 10%
+func (e *cacheEntry) lifetime() time.Duration {
+	cc := parseCacheControl(e.Header.Get("Cache-Control"))
+	if cc.has("max-age") {
+		secs, err := strconv.ParseInt(cc["max-age"], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if expires := e.Header.Get("Expires"); expires != "" {
+		// 无效的 Expires 表示已过期
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(e.date())
+	}
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil {
+		return min(e.date().Sub(lastModified)/10, heuristicMaxLifetime)
+	}
+	return 0
+}
+
+// fresh 判断缓存是否可以不经协商直接使用
+func (e *cacheEntry) fresh(age time.Duration, reqCC cacheControl) bool {
+	if parseCacheControl(e.Header.Get("Cache-Control")).has("no-cache") {
+		return false
+	}
+	// 请求的 max-age=0 常用于强制协商，因此 Age 达到 max-age 即视为过期
+	if reqCC.has("max-age") {
+		secs, err := strconv.ParseInt(reqCC["max-age"], 10, 64)
+		if err != nil || age >= time.Duration(secs)*time.Second {
+			return false
+		}
+	}
+	return age < e.lifetime()
+}
+
+// hasValidators 判断响应是否带有可用于协商的校验信息
+func (e *cacheEntry) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// revalidated 协商成功后用 304 响应的 header 更新缓存（RFC 9111 4.3.4）
+func (e *cacheEntry) revalidated(header http.Header, requestTime, responseTime time.Time) {
+	for k, v := range header {
+		if k == "Content-Length" {
+			continue
+		}
+		e.Header[k] = v
+	}
+	e.RequestTime = requestTime
+	e.ResponseTime = responseTime
+}
+
+// response 根据缓存构造响应
+func (e *cacheEntry) response(req *http.Request, age time.Duration) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl 解析后的 Cache-Control 指令，指令名为小写
+type cacheControl map[string]string
+
+// parseCacheControl 解析 Cache-Control header
+func parseCacheControl(value string) cacheControl {
+	cc := make(cacheControl)
+	for _, part := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		cc[strings.ToLower(name)] = strings.Trim(arg, `"`)
+	}
+	return cc
+}
+
+// has 判断是否包含指令
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// MemoryCache 内存 LRU 缓存存储，超过容量时淘汰最久未使用的条目
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+// memoryCacheItem 内存缓存条目
+type memoryCacheItem struct {
+	key     string
+	value   []byte
+	expires time.Time // 零值表示不过期
+}
+
+// NewMemoryCache 创建内存 LRU 缓存，capacity 不大于 0 时使用 1000
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = defaultCacheSize
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get 实现 CacheStorage 接口
+func (m *MemoryCache) Get(key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if !item.expires.IsZero() && !m.now().Before(item.expires) {
+		m.remove(elem)
+		return nil, false, nil
+	}
+	m.ll.MoveToFront(elem)
+	return item.value, true, nil
+}
+
+// Set 实现 CacheStorage 接口
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = m.now().Add(ttl)
+	}
+	if elem, ok := m.items[key]; ok {
+		item := elem.Value.(*memoryCacheItem)
+		item.value, item.expires = value, expires
+		m.ll.MoveToFront(elem)
+		return nil
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryCacheItem{key: key, value: value, expires: expires})
+	for m.ll.Len() > m.capacity {
+		m.remove(m.ll.Back())
+	}
+	return nil
+}
+
+// Delete 实现 CacheStorage 接口
+func (m *MemoryCache) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.items[key]; ok {
+		m.remove(elem)
+	}
+	return nil
+}
+
+// Len 返回当前条目数
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// remove 删除条目，调用方需持有锁
+func (m *MemoryCache) remove(elem *list.Element) {
+	m.ll.Remove(elem)
+	delete(m.items, elem.Value.(*memoryCacheItem).key)
+}
@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"errors"
+	"time"
+
+	"learning-go/internals/redisx"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache 基于 redisx 的缓存存储，多个实例可共享缓存
+// 使用前需调用 redisx.Init 初始化连接
+type RedisCache struct {
+	prefix string
+}
+
+// NewRedisCache 创建 Redis 缓存存储，prefix 为键前缀，默认 "httpx:cache:"
+// 缓存键不包含鉴权信息，使用不同凭据的客户端应使用不同的前缀
+func NewRedisCache(prefix string) *RedisCache {
+	if prefix == "" {
+		prefix = "httpx:cache:"
+	}
+	return &RedisCache{prefix: prefix}
+}
+
+// Get 实现 CacheStorage 接口
+func (r *RedisCache) Get(key string) ([]byte, bool, error) {
+	value, err := redisx.Get(r.prefix + key)
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return []byte(value), true, nil
+}
+
+// Set 实现 CacheStorage 接口
+func (r *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
+	return redisx.Set(r.prefix+key, value, ttl)
+}
+
+// Delete 实现 CacheStorage 接口
+func (r *RedisCache) Delete(key string) error {
+	return redisx.Del(r.prefix + key)
+}
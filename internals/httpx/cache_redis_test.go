@@ -0,0 +1,73 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"learning-go/internals/redisx"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// ==================== RedisCache 测试 ====================
+
+func TestRedisCache(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("启动 miniredis 失败: %v", err)
+	}
+	defer mr.Close()
+	if err := redisx.Init(redisx.Config{Addr: mr.Addr()}); err != nil {
+		t.Fatalf("初始化 Redis 客户端失败: %v", err)
+	}
+	defer redisx.Close()
+
+	storage := NewRedisCache("test:")
+	client, clock, hits := newCacheClient(t, CacheConfig{Storage: storage}, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"abc"`)
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprintf(w, "v%d", n)
+	})
+
+	getWithHeaders(t, client, "/config", nil)
+	key := "test:" + client.baseURL + "/config"
+	if !mr.Exists(key) {
+		t.Fatalf("Redis 中缺少缓存键 %s，现有 %v", key, mr.Keys())
+	}
+	// 带 ETag 的响应在新鲜期后额外保留 24 小时用于协商
+	if ttl := mr.TTL(key); ttl != 60*time.Second+24*time.Hour {
+		t.Errorf("TTL = %v", ttl)
+	}
+
+	tests := []struct {
+		name     string
+		advance  time.Duration
+		wantBody string
+		wantHits int32
+	}{
+		{name: "新鲜时命中", advance: 30 * time.Second, wantBody: "v1", wantHits: 1},
+		{name: "过期后协商", advance: 31 * time.Second, wantBody: "v1", wantHits: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if _, body := getWithHeaders(t, client, "/config", nil); body != tt.wantBody || hits.Load() != tt.wantHits {
+				t.Errorf("响应 %q、请求 %d 次，期望 %q、%d 次", body, hits.Load(), tt.wantBody, tt.wantHits)
+			}
+		})
+	}
+
+	if err := storage.Delete(client.baseURL + "/config"); err != nil || mr.Exists(key) {
+		t.Errorf("Delete 失败: %v", err)
+	}
+	if _, ok, err := storage.Get("missing"); ok || err != nil {
+		t.Errorf("Get 不存在的键返回 %v, %v", ok, err)
+	}
+}
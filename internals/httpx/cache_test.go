@@ -0,0 +1,344 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// cacheBaseTime 缓存测试的起始时间
+var cacheBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newCacheClient 创建使用假时钟的缓存客户端，handler 的 n 为第几次请求
+// 响应不带 Date，缓存以假时钟记录的收到时间计算 Age
+func newCacheClient(t *testing.T, config CacheConfig, handler func(w http.ResponseWriter, r *http.Request, n int32)) (*Client, *fakeClock, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		handler(w, r, hits.Add(1))
+	})
+	t.Cleanup(server.Close)
+
+	clock := &fakeClock{now: cacheBaseTime}
+	client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1, Cache: &config})
+	client.cache.now = clock.Now
+	return client, clock, &hits
+}
+
+// getWithHeaders 发送 GET 请求并读取响应体
+func getWithHeaders(t *testing.T, client *Client, path string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(context.Background(), path, headers)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("读取响应体失败: %v", err)
+	}
+	return resp, string(body)
+}
+
+// ==================== 缓存语义测试 ====================
+
+func TestCache_Semantics(t *testing.T) {
+	type step struct {
+		advance  time.Duration
+		headers  map[string]string
+		wantBody string
+		wantHits int32
+	}
+
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request, n int32)
+		steps   []step
+	}{
+		{
+			name: "max-age内直接返回",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "public, max-age=60")
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{advance: 30 * time.Second, wantBody: "v1", wantHits: 1},
+				{advance: 31 * time.Second, wantBody: "v2", wantHits: 2},
+			},
+		},
+		{
+			name: "no-store不缓存",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "no-store, max-age=60")
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{wantBody: "v2", wantHits: 2},
+			},
+		},
+		{
+			name: "过期后用ETag协商",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "max-age=10")
+				w.Header().Set("ETag", `"abc"`)
+				if r.Header.Get("If-None-Match") == `"abc"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{advance: 11 * time.Second, wantBody: "v1", wantHits: 2},
+				// 协商成功后重新计算新鲜期
+				{advance: 5 * time.Second, wantBody: "v1", wantHits: 2},
+			},
+		},
+		{
+			name: "no-cache响应每次用Last-Modified协商",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "no-cache")
+				w.Header().Set("Last-Modified", cacheBaseTime.Add(-time.Hour).Format(http.TimeFormat))
+				if r.Header.Get("If-Modified-Since") != "" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{wantBody: "v1", wantHits: 2},
+				{wantBody: "v1", wantHits: 3},
+			},
+		},
+		{
+			name: "内容变化时协商返回新内容",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "max-age=10")
+				w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, n))
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{advance: 11 * time.Second, wantBody: "v2", wantHits: 2},
+				{wantBody: "v2", wantHits: 2},
+			},
+		},
+		{
+			name: "请求no-cache强制协商",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.Header().Set("ETag", `"abc"`)
+				if r.Header.Get("If-None-Match") == `"abc"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{headers: map[string]string{"Cache-Control": "no-cache"}, wantBody: "v1", wantHits: 2},
+				{headers: map[string]string{"Cache-Control": "max-age=0"}, wantBody: "v1", wantHits: 3},
+				{wantBody: "v1", wantHits: 3},
+			},
+		},
+		{
+			name: "Expires",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Expires", cacheBaseTime.Add(time.Minute).Format(http.TimeFormat))
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{advance: 59 * time.Second, wantBody: "v1", wantHits: 1},
+				{advance: time.Second, wantBody: "v2", wantHits: 2},
+			},
+		},
+		{
+			name: "按Last-Modified启发式计算新鲜期",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				// 距今 100 小时，新鲜期为 10 小时
+				w.Header().Set("Last-Modified", cacheBaseTime.Add(-100*time.Hour).Format(http.TimeFormat))
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{advance: 9 * time.Hour, wantBody: "v1", wantHits: 1},
+				{advance: 2 * time.Hour, wantBody: "v2", wantHits: 2},
+			},
+		},
+		{
+			name: "Vary的请求header不同时不命中",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.Header().Set("Vary", "Accept-Language")
+				fmt.Fprintf(w, "%s-%d", r.Header.Get("Accept-Language"), n)
+			},
+			steps: []step{
+				{headers: map[string]string{"Accept-Language": "zh"}, wantBody: "zh-1", wantHits: 1},
+				{headers: map[string]string{"Accept-Language": "zh"}, wantBody: "zh-1", wantHits: 1},
+				{headers: map[string]string{"Accept-Language": "en"}, wantBody: "en-2", wantHits: 2},
+			},
+		},
+		{
+			name: "不可缓存的状态码",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{wantBody: "v2", wantHits: 2},
+			},
+		},
+		{
+			name: "没有新鲜期和校验信息时不缓存",
+			handler: func(w http.ResponseWriter, r *http.Request, n int32) {
+				fmt.Fprintf(w, "v%d", n)
+			},
+			steps: []step{
+				{wantBody: "v1", wantHits: 1},
+				{wantBody: "v2", wantHits: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, clock, hits := newCacheClient(t, CacheConfig{}, tt.handler)
+			for i, s := range tt.steps {
+				clock.Advance(s.advance)
+				_, body := getWithHeaders(t, client, "/config", s.headers)
+				if body != s.wantBody || hits.Load() != s.wantHits {
+					t.Errorf("第 %d 步: 响应 %q、请求 %d 次，期望 %q、%d 次", i+1, body, hits.Load(), s.wantBody, s.wantHits)
+				}
+			}
+		})
+	}
+}
+
+func TestCache_ResponseHeaders(t *testing.T) {
+	client, clock, _ := newCacheClient(t, CacheConfig{}, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1}`))
+	})
+
+	getWithHeaders(t, client, "/config", nil)
+	clock.Advance(30 * time.Second)
+	resp, body := getWithHeaders(t, client, "/config", nil)
+
+	if resp.StatusCode != http.StatusOK || body != `{"id":1}` {
+		t.Errorf("缓存响应 %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Age") != "30" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("缓存响应 header = %v", resp.Header)
+	}
+	if resp.Request == nil || resp.Request.URL.Path != "/config" {
+		t.Error("缓存响应应关联当前请求")
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	client, _, hits := newCacheClient(t, CacheConfig{}, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprintf(w, "v%d", n)
+	})
+
+	getWithHeaders(t, client, "/config", nil)
+	resp, err := client.Post(context.Background(), "/config", map[string]string{"k": "v"}, nil)
+	if err != nil {
+		t.Fatalf("POST 失败: %v", err)
+	}
+	resp.Body.Close()
+
+	// POST 成功后同一 URL 的缓存失效
+	if _, body := getWithHeaders(t, client, "/config", nil); body != "v3" || hits.Load() != 3 {
+		t.Errorf("响应 %q、请求 %d 次，期望 v3、3 次", body, hits.Load())
+	}
+}
+
+func TestCache_LargeBody(t *testing.T) {
+	content := strings.Repeat("x", 100)
+	client, _, hits := newCacheClient(t, CacheConfig{MaxBodySize: 10}, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(content))
+	})
+
+	for i := 1; i <= 2; i++ {
+		// 超过上限的响应不缓存，但响应体完整
+		if _, body := getWithHeaders(t, client, "/large", nil); body != content || hits.Load() != int32(i) {
+			t.Errorf("第 %d 次: 响应 %d 字节、请求 %d 次", i, len(body), hits.Load())
+		}
+	}
+}
+
+func TestCache_QueryIsPartOfKey(t *testing.T) {
+	client, _, hits := newCacheClient(t, CacheConfig{}, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(r.URL.Query().Get("env")))
+	})
+
+	for _, env := range []string{"dev", "prod", "dev", "prod"} {
+		if _, body := getWithHeaders(t, client, "/config?env="+env, nil); body != env {
+			t.Errorf("响应 %q，期望 %q", body, env)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("请求 %d 次，期望 2 次", hits.Load())
+	}
+}
+
+// ==================== MemoryCache 测试 ====================
+
+func TestMemoryCache(t *testing.T) {
+	clock := &fakeClock{now: cacheBaseTime}
+	cache := NewMemoryCache(2)
+	cache.now = clock.Now
+
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+	cache.Get("a")
+	// 容量已满，淘汰最久未使用的 b
+	cache.Set("c", []byte("3"), time.Minute)
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		wantValue string
+		wantOK    bool
+	}{
+		{name: "最近使用的保留", key: "a", wantValue: "1", wantOK: true},
+		{name: "最久未使用的被淘汰", key: "b"},
+		{name: "未过期", key: "c", wantValue: "3", wantOK: true},
+		{name: "过期", advance: time.Minute, key: "c"},
+		{name: "不过期", key: "a", wantValue: "1", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			value, ok, err := cache.Get(tt.key)
+			if err != nil || ok != tt.wantOK || string(value) != tt.wantValue {
+				t.Errorf("Get(%q) = %q, %v, %v，期望 %q, %v", tt.key, value, ok, err, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+
+	cache.Delete("a")
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d，期望 0", cache.Len())
+	}
+}
@@ -0,0 +1,293 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 目标 host 的熔断器处于打开状态，请求未发出
+var ErrCircuitOpen = errors.New("httpx: 熔断器已打开")
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 关闭：请求正常通过
+	CircuitOpen                         // 打开：请求直接失败
+	CircuitHalfOpen                     // 半开：放行少量探测请求
+)
+
+// String 返回状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig 熔断器配置，按 host 分别统计
+type CircuitBreakerConfig struct {
+	// ConsecutiveFailures 连续失败达到该次数时熔断，0 表示不按连续失败熔断
+	ConsecutiveFailures int
+	// FailureRatio 统计窗口内失败比例达到该值时熔断，0 表示不按比例熔断
+	FailureRatio float64
+	// MinRequests 按比例熔断前窗口内至少需要的请求数，默认 10
+	MinRequests int
+	// Window 失败比例的统计窗口，默认 60 秒
+	Window time.Duration
+	// CoolDown 熔断后进入半开状态前的冷却时间，默认 30 秒
+	CoolDown time.Duration
+	// HalfOpenRequests 半开状态放行的探测请求数，全部成功后恢复关闭，默认 1
+	HalfOpenRequests int
+	// IsFailure 判断一次请求是否失败，默认传输错误和 5xx 计为失败；主动取消的请求不计入统计
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange 状态变化回调，可用于告警
+	OnStateChange func(host string, from, to CircuitState)
+}
+
+// circuit 单个 host 的熔断状态
+type circuit struct {
+	state       CircuitState
+	generation  uint64 // 每次状态变化加一，用于丢弃过期的统计结果
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	openedAt    time.Time
+	inFlight    int // 半开状态下正在进行的探测请求
+	successes   int // 半开状态下成功的探测请求
+}
+
+// circuitBreaker 按 host 管理熔断器
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	now      func() time.Time
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// stateChange 一次状态变化，在锁外回调
+type stateChange struct {
+	host     string
+	from, to CircuitState
+}
+
+// newCircuitBreaker 创建熔断器并填充默认配置
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = 60 * time.Second
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaultIsFailure
+	}
+
+	return &circuitBreaker{
+		config:   config,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// defaultIsFailure 传输错误和 5xx 计为失败
+func defaultIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// middleware 熔断中间件，位于重试之内，每次尝试都会经过熔断判断
+// 主动取消的尝试既不算成功也不算失败，只释放半开状态占用的探测名额
+func (b *circuitBreaker) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		host := req.URL.Host
+		generation, err := b.allow(host)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(req)
+		if errors.Is(err, context.Canceled) {
+			b.release(host, generation)
+			return resp, err
+		}
+		b.record(host, generation, b.config.IsFailure(resp, err))
+		return resp, err
+	}
+}
+
+// state 返回 host 当前的熔断状态
+func (b *circuitBreaker) state(host string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		return CircuitClosed
+	}
+	// 冷却结束但还没有新请求时也应视为半开
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.config.CoolDown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow 判断请求能否通过，返回当前代数
+func (b *circuitBreaker) allow(host string) (uint64, error) {
+	b.mu.Lock()
+	var change *stateChange
+	defer func() {
+		b.mu.Unlock()
+		b.notify(change)
+	}()
+
+	c := b.circuit(host)
+	now := b.now()
+
+	switch c.state {
+	case CircuitClosed:
+		if now.Sub(c.windowStart) >= b.config.Window {
+			c.resetCounts(now)
+		}
+	case CircuitOpen:
+		if now.Sub(c.openedAt) < b.config.CoolDown {
+			return 0, ErrCircuitOpen
+		}
+		change = b.setState(host, c, CircuitHalfOpen, now)
+		fallthrough
+	case CircuitHalfOpen:
+		if c.inFlight >= b.config.HalfOpenRequests {
+			return 0, ErrCircuitOpen
+		}
+		c.inFlight++
+	}
+	return c.generation, nil
+}
+
+// record 记录请求结果，必要时切换状态
+func (b *circuitBreaker) record(host string, generation uint64, failed bool) {
+	b.mu.Lock()
+	var change *stateChange
+	defer func() {
+		b.mu.Unlock()
+		b.notify(change)
+	}()
+
+	c := b.circuit(host)
+	// 状态已经变化，本次结果不再参与统计
+	if c.generation != generation {
+		return
+	}
+	now := b.now()
+
+	switch c.state {
+	case CircuitClosed:
+		c.requests++
+		if failed {
+			c.failures++
+			c.consecutive++
+		} else {
+			c.consecutive = 0
+		}
+		if b.shouldTrip(c) {
+			change = b.setState(host, c, CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		c.inFlight--
+		if failed {
+			change = b.setState(host, c, CircuitOpen, now)
+			return
+		}
+		c.successes++
+		if c.successes >= b.config.HalfOpenRequests {
+			change = b.setState(host, c, CircuitClosed, now)
+		}
+	}
+}
+
+// release 放弃一次请求的结果，不改变统计和状态
+func (b *circuitBreaker) release(host string, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	if c.generation == generation && c.state == CircuitHalfOpen {
+		c.inFlight--
+	}
+}
+
+// shouldTrip 判断关闭状态下是否应该熔断
+func (b *circuitBreaker) shouldTrip(c *circuit) bool {
+	if n := b.config.ConsecutiveFailures; n > 0 && c.consecutive >= n {
+		return true
+	}
+	if ratio := b.config.FailureRatio; ratio > 0 && c.requests >= b.config.MinRequests {
+		return float64(c.failures)/float64(c.requests) >= ratio
+	}
+	return false
+}
+
+// circuit 获取 host 对应的熔断状态，不存在时创建
+func (b *circuitBreaker) circuit(host string) *circuit {
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{windowStart: b.now()}
+		b.circuits[host] = c
+	}
+	return c
+}
+
+// setState 切换状态并清空统计
+func (b *circuitBreaker) setState(host string, c *circuit, state CircuitState, now time.Time) *stateChange {
+	change := &stateChange{host: host, from: c.state, to: state}
+	c.state = state
+	c.generation++
+	c.resetCounts(now)
+	if state == CircuitOpen {
+		c.openedAt = now
+	}
+	return change
+}
+
+// resetCounts 开始新的统计窗口
+func (c *circuit) resetCounts(now time.Time) {
+	c.windowStart = now
+	c.requests = 0
+	c.failures = 0
+	c.consecutive = 0
+	c.inFlight = 0
+	c.successes = 0
+}
+
+// notify 触发状态变化回调
+func (b *circuitBreaker) notify(change *stateChange) {
+	if change != nil && b.config.OnStateChange != nil {
+		b.config.OnStateChange(change.host, change.from, change.to)
+	}
+}
+
+// CircuitState 返回 host 当前的熔断状态，未开启熔断时总是 CircuitClosed
+func (c *Client) CircuitState(host string) CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.state(host)
+}
@@ -0,0 +1,259 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestBreaker 创建使用假时钟的熔断器，并记录状态变化
+func newTestBreaker(config CircuitBreakerConfig) (*circuitBreaker, *fakeClock, *[]string) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var changes []string
+	config.OnStateChange = func(host string, from, to CircuitState) {
+		changes = append(changes, fmt.Sprintf("%s:%s->%s", host, from, to))
+	}
+	b := newCircuitBreaker(config)
+	b.now = clock.Now
+	return b, clock, &changes
+}
+
+// call 模拟一次请求，返回是否被放行
+func call(b *circuitBreaker, host string, failed bool) bool {
+	generation, err := b.allow(host)
+	if err != nil {
+		return false
+	}
+	b.record(host, generation, failed)
+	return true
+}
+
+// ==================== 熔断器状态测试 ====================
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	b, clock, changes := newTestBreaker(CircuitBreakerConfig{
+		ConsecutiveFailures: 3,
+		CoolDown:            10 * time.Second,
+	})
+
+	// 成功会重置连续失败计数
+	call(b, "a", true)
+	call(b, "a", true)
+	call(b, "a", false)
+	call(b, "a", true)
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitClosed {
+		t.Fatalf("状态 = %s，期望 closed", got)
+	}
+
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitOpen {
+		t.Fatalf("状态 = %s，期望 open", got)
+	}
+	if _, err := b.allow("a"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("打开状态期望 ErrCircuitOpen，实际 %v", err)
+	}
+
+	// 冷却后进入半开，只放行一个探测请求
+	clock.Advance(10 * time.Second)
+	if got := b.state("a"); got != CircuitHalfOpen {
+		t.Fatalf("状态 = %s，期望 half-open", got)
+	}
+	generation, err := b.allow("a")
+	if err != nil {
+		t.Fatalf("半开状态应放行探测请求: %v", err)
+	}
+	if _, err := b.allow("a"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("半开状态只放行一个请求，实际 %v", err)
+	}
+
+	// 探测成功后恢复关闭
+	b.record("a", generation, false)
+	if got := b.state("a"); got != CircuitClosed {
+		t.Fatalf("状态 = %s，期望 closed", got)
+	}
+
+	want := []string{"a:closed->open", "a:open->half-open", "a:half-open->closed"}
+	if fmt.Sprint(*changes) != fmt.Sprint(want) {
+		t.Errorf("状态变化 = %v，期望 %v", *changes, want)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	b, clock, _ := newTestBreaker(CircuitBreakerConfig{
+		ConsecutiveFailures: 1,
+		CoolDown:            time.Second,
+	})
+
+	call(b, "a", true)
+	clock.Advance(time.Second)
+	call(b, "a", true)
+
+	if got := b.state("a"); got != CircuitOpen {
+		t.Fatalf("状态 = %s，期望 open", got)
+	}
+	// 重新打开后冷却时间重新计算
+	clock.Advance(500 * time.Millisecond)
+	if _, err := b.allow("a"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("期望 ErrCircuitOpen，实际 %v", err)
+	}
+}
+
+func TestCircuitBreaker_FailureRatio(t *testing.T) {
+	b, clock, _ := newTestBreaker(CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       time.Minute,
+	})
+
+	// 请求数不足时不熔断
+	call(b, "a", true)
+	call(b, "a", true)
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitClosed {
+		t.Fatalf("请求数不足时状态 = %s，期望 closed", got)
+	}
+
+	// 窗口过期后重新统计
+	clock.Advance(time.Minute)
+	call(b, "a", false)
+	call(b, "a", false)
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitClosed {
+		t.Fatalf("新窗口状态 = %s，期望 closed", got)
+	}
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitOpen {
+		t.Fatalf("失败比例达到 50%% 后状态 = %s，期望 open", got)
+	}
+}
+
+func TestCircuitBreaker_PerHost(t *testing.T) {
+	b, _, _ := newTestBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1})
+
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitOpen {
+		t.Errorf("host a 状态 = %s，期望 open", got)
+	}
+	if !call(b, "b", false) {
+		t.Error("host b 不应受 host a 影响")
+	}
+}
+
+func TestCircuitBreaker_StaleResultIgnored(t *testing.T) {
+	b, _, _ := newTestBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1})
+
+	// 慢请求在熔断前发出，熔断后才返回
+	slow, _ := b.allow("a")
+	call(b, "a", true)
+	b.record("a", slow, false)
+
+	if got := b.state("a"); got != CircuitOpen {
+		t.Errorf("过期结果不应影响状态，实际 %s", got)
+	}
+}
+
+func TestCircuitBreaker_CanceledNotRecorded(t *testing.T) {
+	b, clock, changes := newTestBreaker(CircuitBreakerConfig{
+		ConsecutiveFailures: 2,
+		CoolDown:            time.Second,
+	})
+	canceled := b.middleware(func(req *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	req, _ := http.NewRequest(http.MethodGet, "http://a/", nil)
+
+	// 取消的请求不重置连续失败计数
+	call(b, "a", true)
+	canceled(req)
+	call(b, "a", true)
+	if got := b.state("a"); got != CircuitOpen {
+		t.Fatalf("状态 = %s，期望 open", got)
+	}
+
+	// 半开状态下取消探测请求不会关闭熔断器，并释放探测名额
+	clock.Advance(time.Second)
+	canceled(req)
+	if got := b.state("a"); got != CircuitHalfOpen {
+		t.Fatalf("取消探测后状态 = %s，期望 half-open", got)
+	}
+	if !call(b, "a", false) {
+		t.Fatal("探测名额未释放")
+	}
+	want := []string{"a:closed->open", "a:open->half-open", "a:half-open->closed"}
+	if fmt.Sprint(*changes) != fmt.Sprint(want) {
+		t.Errorf("状态变化 = %v，期望 %v", *changes, want)
+	}
+}
+
+// ==================== 客户端集成测试 ====================
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	var opened atomic.Bool
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		MaxRetries: 5,
+		RetryDelay: time.Millisecond,
+		CircuitBreaker: &CircuitBreakerConfig{
+			ConsecutiveFailures: 3,
+			CoolDown:            time.Minute,
+			OnStateChange: func(host string, from, to CircuitState) {
+				if to == CircuitOpen {
+					opened.Store(true)
+				}
+			},
+		},
+	})
+
+	// 熔断后剩余的重试直接失败
+	_, err := client.Get(context.Background(), "/test", nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("期望 ErrCircuitOpen，实际 %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("期望请求 3 次，实际 %d 次", got)
+	}
+	if !opened.Load() {
+		t.Error("期望触发 OnStateChange 回调")
+	}
+
+	// 后续请求不再发出
+	if _, err := client.Get(context.Background(), "/test", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("期望 ErrCircuitOpen，实际 %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("熔断后不应再请求，实际 %d 次", got)
+	}
+
+	u, _ := url.Parse(server.URL)
+	if got := client.CircuitState(u.Host); got != CircuitOpen {
+		t.Errorf("CircuitState() = %s，期望 open", got)
+	}
+}
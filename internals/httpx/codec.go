@@ -0,0 +1,135 @@
+package httpx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Codec 请求体和响应体的编解码器，按 Content-Type 选择
+// 内置 JSON、XML 和表单编解码器，protobuf、msgpack 等格式实现该接口后通过 RegisterCodec 注册
+type Codec interface {
+	// ContentType 编码后请求体的 Content-Type，如 application/json
+	ContentType() string
+	// Marshal 编码 v
+	Marshal(v any) ([]byte, error)
+	// Unmarshal 把 data 解码到 v，v 为指针
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec 内置的 JSON 编解码器，也用于 +json 结尾的媒体类型
+var JSONCodec Codec = jsonCodec{}
+
+// XMLCodec 内置的 XML 编解码器，也用于 text/xml 和 +xml 结尾的媒体类型
+var XMLCodec Codec = xmlCodec{}
+
+// FormCodec 内置的 application/x-www-form-urlencoded 编解码器
+// 支持 url.Values、map[string]string 和 map[string][]string 及其指针
+var FormCodec Codec = formCodec{}
+
+// jsonCodec JSON 编解码器
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                { return "application/json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// xmlCodec XML 编解码器
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                { return "application/xml" }
+func (xmlCodec) Marshal(v any) ([]byte, error)      { return xml.Marshal(v) }
+func (xmlCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// formCodec 表单编解码器
+type formCodec struct{}
+
+func (formCodec) ContentType() string { return "application/x-www-form-urlencoded" }
+
+func (formCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case url.Values:
+		return []byte(v.Encode()), nil
+	case *url.Values:
+		return []byte(v.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(v).Encode()), nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for k, s := range v {
+			values.Set(k, s)
+		}
+		return []byte(values.Encode()), nil
+	}
+	return nil, fmt.Errorf("httpx: 表单编码不支持 %T", v)
+}
+
+func (formCodec) Unmarshal(data []byte, v any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+	case *map[string][]string:
+		*v = values
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for k := range values {
+			m[k] = values.Get(k)
+		}
+		*v = m
+	default:
+		return fmt.Errorf("httpx: 表单解码不支持 %T", v)
+	}
+	return nil
+}
+
+// codecs 按媒体类型注册的编解码器
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: map[string]Codec{
+	"application/json":                  JSONCodec,
+	"application/xml":                   XMLCodec,
+	"text/xml":                          XMLCodec,
+	"application/x-www-form-urlencoded": FormCodec,
+}}
+
+// RegisterCodec 按 codec.ContentType() 和额外的 mediaTypes 注册编解码器，同名时覆盖已有的注册
+// 注册后 Client.Post/Put 可通过 Content-Type header 选用，ParseResponse 按响应的 Content-Type 解码
+func RegisterCodec(codec Codec, mediaTypes ...string) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	for _, contentType := range append([]string{codec.ContentType()}, mediaTypes...) {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			codecs.m[mediaType] = codec
+		}
+	}
+}
+
+// CodecFor 返回 Content-Type 对应的编解码器
+// 没有精确注册时，+json 和 +xml 结尾的媒体类型分别使用 JSON 和 XML 编解码器
+func CodecFor(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	codecs.RLock()
+	defer codecs.RUnlock()
+	if codec, ok := codecs.m[mediaType]; ok {
+		return codec, true
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return codecs.m["application/json"], true
+	case strings.HasSuffix(mediaType, "+xml"):
+		return codecs.m["application/xml"], true
+	}
+	return nil, false
+}
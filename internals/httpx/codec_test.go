@@ -0,0 +1,201 @@
+package httpx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// xmlUser XML 格式的用户
+type xmlUser struct {
+	XMLName xml.Name `xml:"user"`
+	ID      int      `xml:"id"`
+	Name    string   `xml:"name"`
+}
+
+// kvCodec 测试用的第三方编解码器，格式为 key=value 的单行文本
+type kvCodec struct{}
+
+func (kvCodec) ContentType() string { return "application/x-kv" }
+
+func (kvCodec) Marshal(v any) ([]byte, error) {
+	u, ok := v.(user)
+	if !ok {
+		return nil, fmt.Errorf("kv 编码不支持 %T", v)
+	}
+	return fmt.Appendf(nil, "id=%d;name=%s", u.ID, u.Name), nil
+}
+
+func (kvCodec) Unmarshal(data []byte, v any) error {
+	u, ok := v.(*user)
+	if !ok {
+		return fmt.Errorf("kv 解码不支持 %T", v)
+	}
+	_, err := fmt.Sscanf(strings.Replace(string(data), ";name=", " ", 1), "id=%d %s", &u.ID, &u.Name)
+	return err
+}
+
+func init() {
+	RegisterCodec(kvCodec{}, "application/vnd.kv")
+}
+
+// ==================== 编解码器选择测试 ====================
+
+func TestCodecFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        Codec
+		wantOK      bool
+	}{
+		{name: "JSON", contentType: "application/json", want: JSONCodec, wantOK: true},
+		{name: "带参数", contentType: "application/json; charset=utf-8", want: JSONCodec, wantOK: true},
+		{name: "大小写不敏感", contentType: "Application/XML", want: XMLCodec, wantOK: true},
+		{name: "text/xml", contentType: "text/xml; charset=gbk", want: XMLCodec, wantOK: true},
+		{name: "+json后缀", contentType: "application/problem+json", want: JSONCodec, wantOK: true},
+		{name: "+xml后缀", contentType: "application/atom+xml", want: XMLCodec, wantOK: true},
+		{name: "表单", contentType: "application/x-www-form-urlencoded", want: FormCodec, wantOK: true},
+		{name: "注册的编解码器", contentType: "application/x-kv", want: kvCodec{}, wantOK: true},
+		{name: "注册的别名", contentType: "application/vnd.kv", want: kvCodec{}, wantOK: true},
+		{name: "未注册", contentType: "text/html", wantOK: false},
+		{name: "空", contentType: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CodecFor(tt.contentType)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("CodecFor(%q) = %T, %v，期望 %T, %v", tt.contentType, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// ==================== 请求编码测试 ====================
+
+func TestCodec_Post(t *testing.T) {
+	tests := []struct {
+		name            string
+		codec           Codec
+		method          string
+		data            any
+		headers         map[string]string
+		wantContentType string
+		wantBody        string
+		wantErr         bool
+	}{
+		{name: "默认JSON", data: user{ID: 1, Name: "alice"}, wantContentType: "application/json", wantBody: `{"id":1,"name":"alice"}`},
+		{name: "配置XML", codec: XMLCodec, data: xmlUser{ID: 1, Name: "alice"}, wantContentType: "application/xml", wantBody: "<user><id>1</id><name>alice</name></user>"},
+		{
+			name:            "header指定XML",
+			method:          http.MethodPut,
+			data:            xmlUser{ID: 2, Name: "bob"},
+			headers:         map[string]string{"content-type": "text/xml; charset=utf-8"},
+			wantContentType: "text/xml; charset=utf-8",
+			wantBody:        "<user><id>2</id><name>bob</name></user>",
+		},
+		{name: "表单", codec: FormCodec, data: map[string]string{"b": "2", "a": "1"}, wantContentType: "application/x-www-form-urlencoded", wantBody: "a=1&b=2"},
+		{name: "注册的编解码器", codec: kvCodec{}, data: user{ID: 3, Name: "carol"}, wantContentType: "application/x-kv", wantBody: "id=3;name=carol"},
+		{name: "未注册的Content-Type使用默认编解码器", data: user{ID: 1, Name: "dave"}, headers: map[string]string{"content-type": "text/plain"}, wantContentType: "application/json", wantBody: `{"id":1,"name":"dave"}`},
+		{name: "编码失败", codec: FormCodec, data: user{ID: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got := r.Header.Get("Content-Type"); got != tt.wantContentType {
+					t.Errorf("Content-Type = %q，期望 %q", got, tt.wantContentType)
+				}
+				if string(body) != tt.wantBody {
+					t.Errorf("请求体 = %q，期望 %q", body, tt.wantBody)
+				}
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, Codec: tt.codec})
+			var resp *http.Response
+			var err error
+			if tt.method == http.MethodPut {
+				resp, err = client.Put(context.Background(), "/users", tt.data, tt.headers)
+			} else {
+				resp, err = client.Post(context.Background(), "/users", tt.data, tt.headers)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+// ==================== 响应解码测试 ====================
+
+func TestCodec_ParseResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        user
+		wantErr     bool
+	}{
+		{name: "JSON", contentType: "application/json", body: `{"id":1,"name":"alice"}`, want: user{ID: 1, Name: "alice"}},
+		{name: "+json后缀", contentType: "application/vnd.api+json", body: `{"id":2,"name":"bob"}`, want: user{ID: 2, Name: "bob"}},
+		{name: "XML", contentType: "application/xml; charset=utf-8", body: "<user><ID>3</ID><Name>carol</Name></user>", want: user{ID: 3, Name: "carol"}},
+		{name: "注册的编解码器", contentType: "application/x-kv", body: "id=4;name=dave", want: user{ID: 4, Name: "dave"}},
+		{name: "未注册时按JSON解析", contentType: "text/plain", body: `{"id":5,"name":"eve"}`, want: user{ID: 5, Name: "eve"}},
+		{name: "无效XML", contentType: "application/xml", body: "<user><ID>x</ID></user>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL})
+			resp, err := client.Get(context.Background(), "/users/1", nil)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			got, err := ParseResponse[user](resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResponse() error = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseResponse() = %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormCodec(t *testing.T) {
+	data := []byte("access_token=abc&scope=read&scope=write")
+
+	var values url.Values
+	if err := FormCodec.Unmarshal(data, &values); err != nil || len(values["scope"]) != 2 {
+		t.Errorf("解码到 url.Values = %v, %v", values, err)
+	}
+	var m map[string]string
+	if err := FormCodec.Unmarshal(data, &m); err != nil || m["access_token"] != "abc" || m["scope"] != "read" {
+		t.Errorf("解码到 map[string]string = %v, %v", m, err)
+	}
+	var u user
+	if err := FormCodec.Unmarshal(data, &u); err == nil {
+		t.Error("期望解码到结构体失败")
+	}
+	if out, err := FormCodec.Marshal(values); err != nil || string(out) != string(data) {
+		t.Errorf("编码 url.Values = %q, %v", out, err)
+	}
+}
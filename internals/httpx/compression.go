@@ -0,0 +1,341 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding 内容编码的实现
+// 内置 gzip、deflate、br 和 zstd，其他编码实现该接口后通过 CompressionConfig.Encodings 注册
+type Encoding interface {
+	// Name 返回 Content-Encoding 中的名称，如 gzip
+	Name() string
+	// NewWriter 返回压缩写入器，关闭时写入剩余数据，不关闭 w
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader 返回解压读取器
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipEncoding 内置的 gzip 编码
+var GzipEncoding Encoding = gzipEncoding{}
+
+// DeflateEncoding 内置的 deflate 编码，发送时使用 zlib 格式（RFC 9110 8.4.1.2），
+// 接收时兼容部分服务端发送的不带 zlib 头的原始 deflate 数据
+var DeflateEncoding Encoding = deflateEncoding{}
+
+// BrotliEncoding 内置的 br 编码
+var BrotliEncoding Encoding = brotliEncoding{}
+
+// ZstdEncoding 内置的 zstd 编码，解码窗口限制为 RFC 9659 规定的 8MB
+var ZstdEncoding Encoding = zstdEncoding{}
+
+// gzipEncoding gzip 编码
+type gzipEncoding struct{}
+
+func (gzipEncoding) Name() string { return "gzip" }
+
+func (gzipEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateEncoding deflate 编码
+type deflateEncoding struct{}
+
+func (deflateEncoding) Name() string { return "deflate" }
+
+func (deflateEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func (deflateEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	// zlib 头：CM 为 8，且前两个字节按大端序是 31 的倍数
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// brotliEncoding br 编码
+type brotliEncoding struct{}
+
+func (brotliEncoding) Name() string { return "br" }
+
+func (brotliEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return brotli.NewWriter(w), nil
+}
+
+func (brotliEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
+
+// zstdEncoding zstd 编码
+type zstdEncoding struct{}
+
+func (zstdEncoding) Name() string { return "zstd" }
+
+func (zstdEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(zstdMaxWindow))
+}
+
+func (zstdEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	// 单协程解码，关闭时释放资源
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(zstdMaxWindow))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// zstdMaxWindow HTTP 中 zstd 编码的最大窗口（RFC 9659）
+const zstdMaxWindow = 8 << 20
+
+// CompressionConfig 请求体压缩和响应解码配置
+// 开启后客户端显式发送 Accept-Encoding 并自行解码响应，不再依赖 http.Transport 的透明 gzip；
+// 带 Range 的请求不声明 Accept-Encoding，响应保持原样
+type CompressionConfig struct {
+	// RequestEncoding 压缩请求体使用的编码，如 "gzip"，为空时不压缩请求体
+	RequestEncoding string
+	// MinSize 请求体达到该大小才压缩，默认 1KB；长度未知的流式请求体总是压缩
+	MinSize int64
+	// AcceptEncodings 声明接受并自动解码的响应编码，按优先级排列，默认 zstd、br、gzip、deflate
+	AcceptEncodings []string
+	// Encodings 额外的编码实现，与内置编码同名时覆盖内置实现
+	Encodings []Encoding
+}
+
+// compressor 压缩中间件
+type compressor struct {
+	encodings      map[string]Encoding
+	request        Encoding
+	minSize        int64
+	acceptEncoding string
+}
+
+// newCompressor 校验编码配置并创建压缩中间件
+func newCompressor(config CompressionConfig) (*compressor, error) {
+	c := &compressor{
+		encodings: map[string]Encoding{
+			"gzip":    GzipEncoding,
+			"deflate": DeflateEncoding,
+			"br":      BrotliEncoding,
+			"zstd":    ZstdEncoding,
+		},
+		minSize: config.MinSize,
+	}
+	for _, e := range config.Encodings {
+		c.encodings[strings.ToLower(e.Name())] = e
+	}
+	if c.minSize <= 0 {
+		c.minSize = 1 << 10
+	}
+
+	if config.RequestEncoding != "" {
+		e, ok := c.encodings[strings.ToLower(config.RequestEncoding)]
+		if !ok {
+			return nil, fmt.Errorf("httpx: 未注册请求体编码 %q", config.RequestEncoding)
+		}
+		c.request = e
+	}
+
+	accept := config.AcceptEncodings
+	if len(accept) == 0 {
+		accept = []string{"zstd", "br", "gzip", "deflate"}
+	}
+	for _, name := range accept {
+		if _, ok := c.encodings[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("httpx: 未注册响应编码 %q", name)
+		}
+	}
+	c.acceptEncoding = strings.Join(accept, ", ")
+	return c, nil
+}
+
+// middleware 压缩中间件，位于重试之外：请求体只压缩一次，重试时重新发送压缩后的内容
+// 修改的是请求的副本，外层的缓存等中间件看到的仍是调用方的原始 header
+func (c *compressor) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		if c.request != nil {
+			if err := c.compressRequest(req); err != nil {
+				return nil, err
+			}
+		}
+		// 与 http.Transport 一致，Range 请求不协商编码：服务端对编码后的内容取的片段无法单独解码
+		partial := req.Header.Get("Range") != ""
+		if !partial && req.Header.Get("Accept-Encoding") == "" {
+			req.Header.Set("Accept-Encoding", c.acceptEncoding)
+		}
+
+		resp, err := next(req)
+		if err != nil || partial {
+			return resp, err
+		}
+		if err := c.decodeResponse(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// compressRequest 压缩请求体，已设置 Content-Encoding 或小于 MinSize 时不处理
+// 长度已知的请求体在内存中压缩，可以重试；流式请求体边读边压缩，可重放性与原请求体一致
+func (c *compressor) compressRequest(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	// 请求体不为空时 ContentLength 为 0 表示长度未知
+	if req.ContentLength > 0 && req.ContentLength < c.minSize {
+		return nil
+	}
+
+	if req.ContentLength <= 0 {
+		body, getBody := req.Body, req.GetBody
+		current := c.compressStream(body)
+		req.Body = current
+		if getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				// 上一次尝试的压缩协程可能仍在读取原请求体，退出后才能重置
+				current.closeAndWait()
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				current = c.compressStream(body)
+				return current, nil
+			}
+		}
+	} else {
+		var buf bytes.Buffer
+		w, err := c.request.NewWriter(&buf)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		data := buf.Bytes()
+		req.ContentLength = int64(len(data))
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+	req.Header.Set("Content-Encoding", c.request.Name())
+	req.Header.Del("Content-Length")
+	return nil
+}
+
+// compressStream 通过管道边读边压缩
+func (c *compressor) compressStream(body io.ReadCloser) *streamBody {
+	return &streamBody{
+		lazyPipe: newLazyPipe(func(pw io.Writer) error {
+			defer body.Close()
+			w, err := c.request.NewWriter(pw)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(w, body); err != nil {
+				return err
+			}
+			return w.Close()
+		}),
+		body: body,
+	}
+}
+
+// streamBody 压缩中的流式请求体，关闭时同时关闭原请求体
+type streamBody struct {
+	*lazyPipe
+	body io.Closer
+}
+
+// Close 关闭管道和原请求体
+func (b *streamBody) Close() error {
+	b.lazyPipe.Close()
+	return b.body.Close()
+}
+
+// decodeResponse 按 Content-Encoding 解码响应体，未注册的编码保持原样
+// 多个编码按应用的相反顺序解码
+func (c *compressor) decodeResponse(resp *http.Response) error {
+	var names []string
+	for _, v := range resp.Header.Values("Content-Encoding") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" && name != "identity" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 || resp.ContentLength == 0 || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return nil
+	}
+	for _, name := range names {
+		if _, ok := c.encodings[name]; !ok {
+			return nil
+		}
+	}
+
+	body := resp.Body
+	var reader io.Reader = body
+	closers := []io.Closer{body}
+	for i := len(names) - 1; i >= 0; i-- {
+		r, err := c.encodings[names[i]].NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("httpx: 解码 %s 响应失败: %w", names[i], err)
+		}
+		reader = r
+		closers = append(closers, r)
+	}
+
+	resp.Body = &decodedBody{Reader: reader, closers: closers}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody 解码后的响应体，关闭时依次关闭解码器和原响应体
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close 关闭解码器和原响应体
+func (b *decodedBody) Close() error {
+	var err error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if e := b.closers[i].Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
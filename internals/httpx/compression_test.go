@@ -0,0 +1,391 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// customEncoding 测试用的第三方编码，内部使用原始 flate 模拟
+type customEncoding struct{}
+
+func (customEncoding) Name() string { return "x-custom" }
+
+func (customEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.BestSpeed)
+}
+
+func (customEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+// compressBytes 按 encodings 的顺序依次压缩
+func compressBytes(t *testing.T, data []byte, encodings ...string) []byte {
+	t.Helper()
+	for _, name := range encodings {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch name {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		case "br":
+			w = brotli.NewWriter(&buf)
+		case "zstd":
+			w, _ = zstd.NewWriter(&buf)
+		case "x-custom":
+			w, _ = customEncoding{}.NewWriter(&buf)
+		}
+		w.Write(data)
+		w.Close()
+		data = buf.Bytes()
+	}
+	return data
+}
+
+// decompressRequest 按 Content-Encoding 解压请求体
+func decompressRequest(t *testing.T, r *http.Request) string {
+	t.Helper()
+	var reader io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("gzip 请求体无效: %v", err)
+		}
+		reader = gr
+	case "deflate":
+		zr, err := zlib.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("deflate 请求体无效: %v", err)
+		}
+		reader = zr
+	case "br":
+		reader = brotli.NewReader(r.Body)
+	case "zstd":
+		zr, err := zstd.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("zstd 请求体无效: %v", err)
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("读取请求体失败: %v", err)
+	}
+	return string(data)
+}
+
+// ==================== 请求体压缩测试 ====================
+
+func TestCompression_Request(t *testing.T) {
+	large := strings.Repeat(`{"name":"item","value":12345}`, 100)
+
+	tests := []struct {
+		name         string
+		config       CompressionConfig
+		body         func() io.Reader
+		headers      map[string]string
+		wantEncoding string
+		wantChunked  bool
+	}{
+		{name: "超过阈值时gzip压缩", config: CompressionConfig{RequestEncoding: "gzip"}, body: func() io.Reader { return strings.NewReader(large) }, wantEncoding: "gzip"},
+		{name: "deflate压缩", config: CompressionConfig{RequestEncoding: "deflate"}, body: func() io.Reader { return strings.NewReader(large) }, wantEncoding: "deflate"},
+		{name: "br压缩", config: CompressionConfig{RequestEncoding: "br"}, body: func() io.Reader { return strings.NewReader(large) }, wantEncoding: "br"},
+		{name: "zstd压缩", config: CompressionConfig{RequestEncoding: "zstd"}, body: func() io.Reader { return strings.NewReader(large) }, wantEncoding: "zstd"},
+		{name: "低于阈值时不压缩", config: CompressionConfig{RequestEncoding: "gzip", MinSize: 1 << 20}, body: func() io.Reader { return strings.NewReader(large) }},
+		{name: "未配置请求编码时不压缩", config: CompressionConfig{}, body: func() io.Reader { return strings.NewReader(large) }},
+		{name: "已设置Content-Encoding时不处理", config: CompressionConfig{RequestEncoding: "gzip"}, body: func() io.Reader { return strings.NewReader(large) }, headers: map[string]string{"Content-Encoding": "identity"}},
+		{name: "长度未知的流式请求体", config: CompressionConfig{RequestEncoding: "gzip", MinSize: 1 << 20}, body: func() io.Reader { return io.MultiReader(strings.NewReader(large)) }, wantEncoding: "gzip", wantChunked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				encoding := r.Header.Get("Content-Encoding")
+				if encoding == "identity" {
+					encoding = ""
+				}
+				if encoding != tt.wantEncoding {
+					t.Errorf("Content-Encoding = %q，期望 %q", encoding, tt.wantEncoding)
+				}
+				if tt.wantEncoding != "" && !tt.wantChunked && r.ContentLength >= int64(len(large)) {
+					t.Errorf("压缩后长度 %d 未减小", r.ContentLength)
+				}
+				if (r.ContentLength == -1) != tt.wantChunked {
+					t.Errorf("ContentLength = %d", r.ContentLength)
+				}
+				if got := decompressRequest(t, r); got != large {
+					t.Errorf("请求体内容不一致，长度 %d", len(got))
+				}
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, Compression: &tt.config})
+			resp, err := client.Request(context.Background(), http.MethodPost, "/upload", tt.body(), tt.headers)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestCompression_RequestRetry(t *testing.T) {
+	large := strings.Repeat("abcdefgh", 1000)
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := decompressRequest(t, r); got != large {
+			t.Errorf("第 %d 次请求体不完整，长度 %d", attempts.Load()+1, len(got))
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		RetryDelay:  1,
+		Compression: &CompressionConfig{RequestEncoding: "gzip"},
+	})
+
+	tests := []struct {
+		name string
+		body func() io.Reader
+	}{
+		{name: "长度已知", body: func() io.Reader { return strings.NewReader(large) }},
+		{name: "可Seek的流式请求体", body: func() io.Reader { return struct{ io.ReadSeeker }{strings.NewReader(large)} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts.Store(0)
+			resp, err := client.Request(context.Background(), http.MethodPost, "/upload", tt.body(), nil)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+				t.Errorf("状态码 %d、请求 %d 次", resp.StatusCode, attempts.Load())
+			}
+		})
+	}
+}
+
+func TestCompression_RequestRetryUnread(t *testing.T) {
+	// 随机内容无法压缩，压缩协程会阻塞在写入管道上
+	content := make([]byte, 1<<20)
+	rand.NewChaCha8([32]byte{}).Read(content)
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次不读取请求体直接返回，上一次的压缩协程可能仍在读取原请求体
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if got := decompressRequest(t, r); got != string(content) {
+			t.Errorf("重试的请求体不完整，长度 %d", len(got))
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		RetryDelay:  1,
+		Compression: &CompressionConfig{RequestEncoding: "gzip"},
+	})
+	resp, err := client.Request(context.Background(), http.MethodPost, "/upload", &slowSeeker{bytes.NewReader(content)}, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("状态码 %d、请求 %d 次", resp.StatusCode, attempts.Load())
+	}
+}
+
+// ==================== 响应解码测试 ====================
+
+func TestCompression_Response(t *testing.T) {
+	content := strings.Repeat("hello compression ", 50)
+	const defaultAccept = "zstd, br, gzip, deflate"
+
+	tests := []struct {
+		name         string
+		config       CompressionConfig
+		encoding     string
+		body         []byte
+		wantAccept   string
+		wantBody     string
+		wantEncoding string
+		wantErr      bool
+	}{
+		{name: "gzip", encoding: "gzip", body: compressBytes(t, []byte(content), "gzip"), wantAccept: defaultAccept, wantBody: content},
+		{name: "zlib格式的deflate", encoding: "deflate", body: compressBytes(t, []byte(content), "deflate"), wantAccept: defaultAccept, wantBody: content},
+		{name: "原始deflate", encoding: "deflate", body: compressBytes(t, []byte(content), "raw-deflate"), wantAccept: defaultAccept, wantBody: content},
+		{name: "br", encoding: "br", body: compressBytes(t, []byte(content), "br"), wantAccept: defaultAccept, wantBody: content},
+		{name: "zstd", encoding: "zstd", body: compressBytes(t, []byte(content), "zstd"), wantAccept: defaultAccept, wantBody: content},
+		{name: "多重编码", encoding: "deflate, gzip", body: compressBytes(t, []byte(content), "deflate", "gzip"), wantAccept: defaultAccept, wantBody: content},
+		{name: "未压缩", body: []byte(content), wantAccept: defaultAccept, wantBody: content},
+		{
+			name:       "注册的编码",
+			config:     CompressionConfig{AcceptEncodings: []string{"x-custom", "gzip"}, Encodings: []Encoding{customEncoding{}}},
+			encoding:   "x-custom",
+			body:       compressBytes(t, []byte(content), "x-custom"),
+			wantAccept: "x-custom, gzip",
+			wantBody:   content,
+		},
+		{name: "未注册的编码保持原样", encoding: "compress", body: []byte("raw-lzw-bytes"), wantAccept: defaultAccept, wantBody: "raw-lzw-bytes", wantEncoding: "compress"},
+		{name: "无效的gzip数据", encoding: "gzip", body: []byte("not gzip"), wantAccept: defaultAccept, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept-Encoding"); got != tt.wantAccept {
+					t.Errorf("Accept-Encoding = %q，期望 %q", got, tt.wantAccept)
+				}
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(tt.body)
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, Compression: &tt.config})
+			resp, err := client.Get(context.Background(), "/data", nil)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("期望解码失败")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			data, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil || string(data) != tt.wantBody {
+				t.Errorf("响应体 %q, %v", data, err)
+			}
+			if got := resp.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("解码后 Content-Encoding = %q，期望 %q", got, tt.wantEncoding)
+			}
+		})
+	}
+}
+
+func TestCompression_WithCache(t *testing.T) {
+	content := strings.Repeat("cached compression ", 50)
+	encoded := compressBytes(t, []byte(content), "gzip")
+	var hits atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(encoded)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		Cache:       &CacheConfig{},
+		Compression: &CompressionConfig{},
+	})
+	// 缓存的是解码后的响应体，调用方的 Accept-Encoding 不同也命中
+	for i, headers := range []map[string]string{nil, nil, {"Accept-Encoding": "gzip"}} {
+		_, body := getWithHeaders(t, client, "/data", headers)
+		if body != content {
+			t.Errorf("第 %d 次响应体长度 %d，期望 %d", i+1, len(body), len(content))
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("服务端收到 %d 次请求，期望 1", got)
+	}
+}
+
+func TestCompression_DownloadResume(t *testing.T) {
+	const partial = 40000
+	encoded := compressBytes(t, downloadContent, "gzip")
+	var gotRange, gotAccept string
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		gotRange, gotAccept = r.Header.Get("Range"), r.Header.Get("Accept-Encoding")
+		// 接受 gzip 时对编码后的内容取范围
+		content := downloadContent
+		if strings.Contains(gotAccept, "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			content = encoded
+		}
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	})
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(filename, downloadContent[:partial], 0o600)
+
+	client := NewClient(Config{BaseURL: server.URL, Compression: &CompressionConfig{}})
+	n, err := client.DownloadFile(context.Background(), "/data", filename, &DownloadOptions{Resume: true, Checksum: downloadChecksum()})
+	if err != nil {
+		t.Fatalf("续传失败: %v", err)
+	}
+	if gotRange != "bytes=40000-" || gotAccept != "" {
+		t.Errorf("Range = %q、Accept-Encoding = %q，期望不协商编码", gotRange, gotAccept)
+	}
+	data, _ := os.ReadFile(filename)
+	if n != int64(len(downloadContent)) || !bytes.Equal(data, downloadContent) {
+		t.Errorf("返回 %d，文件内容与期望一致 %v", n, bytes.Equal(data, downloadContent))
+	}
+}
+
+func TestCompression_Config(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CompressionConfig
+		wantErr string
+	}{
+		{name: "默认配置", config: CompressionConfig{}},
+		{name: "未注册的请求编码", config: CompressionConfig{RequestEncoding: "x-custom"}, wantErr: "未注册请求体编码"},
+		{name: "未注册的响应编码", config: CompressionConfig{AcceptEncodings: []string{"compress"}}, wantErr: "未注册响应编码"},
+		{name: "注册后可用于请求", config: CompressionConfig{RequestEncoding: "x-custom", Encodings: []Encoding{customEncoding{}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Compression: &tt.config})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("New() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v，期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DedupConfig 合并并发相同请求的配置
+// 方法、URL 和 header 都相同的并发 GET/HEAD 请求共享一次实际请求，每个调用方得到独立的响应副本
+type DedupConfig struct {
+	// Headers 用于区分请求的 header，为空时所有 header 都参与比较
+	Headers []string
+	// MaxBodySize 共享响应体的上限，默认 10MB
+	// 超过时第一个等待方直接读取原响应，其余等待方各自重新发送请求；
+	// 只有一个等待方或 text/event-stream 响应同样不共享，响应直接交给第一个等待方
+	MaxBodySize int64
+}
+
+// dedupCall 一次进行中的共享请求
+type dedupCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	// 以下字段在 done 关闭后可读
+	resp   *http.Response // 响应的状态和 header，不含响应体
+	body   []byte
+	err    error
+	large  bool           // 响应体未共享：只有一个等待方、不适合共享或超过上限
+	stream *http.Response // 未共享的原响应，由第一个等待方取走
+}
+
+// deduplicator 合并请求中间件
+type deduplicator struct {
+	config DedupConfig
+	mu     sync.Mutex
+	calls  map[string]*dedupCall
+}
+
+// newDeduplicator 创建合并请求中间件并填充默认配置
+func newDeduplicator(config DedupConfig) *deduplicator {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 10 << 20
+	}
+	return &deduplicator{config: config, calls: make(map[string]*dedupCall)}
+}
+
+// middleware 合并请求中间件，位于重试之外，共享请求的重试对所有等待方只进行一次
+// 共享请求在独立的 context 中执行，保留第一个调用方 context 中的值；
+// 单个调用方取消只影响自己，所有调用方都取消后共享请求才会取消
+func (d *deduplicator) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return next(req)
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			return next(req)
+		}
+
+		key := d.key(req)
+		d.mu.Lock()
+		call, ok := d.calls[key]
+		if !ok {
+			ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+			call = &dedupCall{done: make(chan struct{}), cancel: cancel}
+			d.calls[key] = call
+			go d.run(key, call, next, req.WithContext(ctx))
+		}
+		call.waiters++
+		d.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-req.Context().Done():
+			d.leave(key, call)
+			return nil, req.Context().Err()
+		}
+
+		if call.err != nil {
+			return nil, call.err
+		}
+		if call.large {
+			d.mu.Lock()
+			stream := call.stream
+			call.stream = nil
+			d.mu.Unlock()
+			if stream != nil {
+				// 共享请求不受调用方 context 控制，取走原响应后由调用方 context 负责中断
+				stop := context.AfterFunc(req.Context(), call.cancel)
+				stream.Body = &cancelBody{Reader: stream.Body, body: stream.Body, cancel: func() { stop() }}
+				stream.Request = req
+				return stream, nil
+			}
+			return next(req)
+		}
+
+		resp := *call.resp
+		resp.Header = call.resp.Header.Clone()
+		resp.Trailer = call.resp.Trailer.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(call.body))
+		resp.ContentLength = int64(len(call.body))
+		resp.Request = req
+		return &resp, nil
+	}
+}
+
+// run 发送共享请求并读取响应体
+func (d *deduplicator) run(key string, call *dedupCall, next RoundTripFunc, req *http.Request) {
+	resp, err := next(req)
+	if err == nil && d.passThrough(key, call, resp) {
+		return
+	}
+	var body []byte
+	var stream *http.Response
+	if err == nil {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, d.config.MaxBodySize+1))
+		if err == nil && int64(len(body)) > d.config.MaxBodySize {
+			// 原响应继续读取时需要保持 context 有效，关闭响应体时再取消
+			stream = resp
+			stream.Body = &cancelBody{
+				Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+				body:   resp.Body,
+				cancel: call.cancel,
+			}
+			body = nil
+		} else {
+			resp.Body.Close()
+		}
+	}
+	if stream == nil {
+		call.cancel()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.calls[key] == call {
+		delete(d.calls, key)
+	}
+	call.resp, call.body, call.err = resp, body, err
+	call.large, call.stream = stream != nil, stream
+	if call.waiters == 0 && call.stream != nil {
+		call.stream.Body.Close()
+		call.stream = nil
+	}
+	close(call.done)
+}
+
+// passThrough 只有一个等待方或响应不适合共享时，不读取响应体，直接把原响应交给第一个等待方
+// 在读取响应体之前根据 header 判断，SSE 等流式响应不会因为等待读完而阻塞
+func (d *deduplicator) passThrough(key string, call *dedupCall, resp *http.Response) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if call.waiters > 1 && d.shareable(resp) {
+		return false
+	}
+
+	// 之后到达的相同请求各自发送
+	if d.calls[key] == call {
+		delete(d.calls, key)
+	}
+	resp.Body = &cancelBody{Reader: resp.Body, body: resp.Body, cancel: call.cancel}
+	call.resp, call.large, call.stream = resp, true, resp
+	if call.waiters == 0 {
+		call.stream.Body.Close()
+		call.stream = nil
+	}
+	close(call.done)
+	return true
+}
+
+// shareable 根据 header 判断响应体能否读入内存共享
+// 事件流没有结尾，已知超过上限的响应体也不读取
+func (d *deduplicator) shareable(resp *http.Response) bool {
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/event-stream" {
+		return false
+	}
+	return resp.ContentLength <= d.config.MaxBodySize
+}
+
+// leave 等待方取消，所有等待方都离开后取消共享请求
+func (d *deduplicator) leave(key string, call *dedupCall) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	if d.calls[key] == call {
+		delete(d.calls, key)
+	}
+	call.cancel()
+	if call.stream != nil {
+		call.stream.Body.Close()
+		call.stream = nil
+	}
+}
+
+// key 根据方法、URL 和 header 生成请求的标识
+func (d *deduplicator) key(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte(' ')
+	b.WriteString(req.URL.String())
+
+	names := d.config.Headers
+	if len(names) == 0 {
+		names = make([]string, 0, len(req.Header))
+		for name := range req.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		b.WriteByte('\n')
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteByte(':')
+		b.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return b.String()
+}
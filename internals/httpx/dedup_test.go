@@ -0,0 +1,397 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// waitWaiters 等待进行中的共享请求共有 n 个等待方
+func waitWaiters(t *testing.T, d *deduplicator, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.Lock()
+		total := 0
+		for _, call := range d.calls {
+			total += call.waiters
+		}
+		d.mu.Unlock()
+		if total == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("等待方未达到 %d 个", n)
+}
+
+// dedupResult 并发请求的结果
+type dedupResult struct {
+	body string
+	err  error
+}
+
+// concurrentGets 并发发送请求，每个请求使用对应的 header
+func concurrentGets(ctx context.Context, client *Client, path string, headers []map[string]string) []dedupResult {
+	results := make([]dedupResult, len(headers))
+	var wg sync.WaitGroup
+	for i, h := range headers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(ctx, path, h)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			results[i] = dedupResult{body: string(data), err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// ==================== 合并请求测试 ====================
+
+func TestDedup_ConcurrentRequests(t *testing.T) {
+	const numRequests = 10
+	release := make(chan struct{})
+	var hits atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Dedup: &DedupConfig{}})
+
+	headers := make([]map[string]string, numRequests)
+	done := make(chan []dedupResult)
+	go func() { done <- concurrentGets(context.Background(), client, "/test", headers) }()
+	waitWaiters(t, client.dedup, numRequests)
+	close(release)
+
+	for i, r := range <-done {
+		if r.err != nil || r.body != `{"status":"ok"}` {
+			t.Errorf("第 %d 个请求: %q, %v", i, r.body, r.err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("实际请求 %d 次，期望 1 次", hits.Load())
+	}
+
+	// 请求结束后不再共享
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if hits.Load() != 2 {
+		t.Errorf("实际请求 %d 次，期望 2 次", hits.Load())
+	}
+}
+
+func TestDedup_IndependentCopies(t *testing.T) {
+	release := make(chan struct{})
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("X-Version", "1")
+		w.Write([]byte("shared"))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Dedup: &DedupConfig{}})
+
+	responses := make([]*http.Response, 2)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], _ = client.Get(context.Background(), "/test", nil)
+		}()
+	}
+	waitWaiters(t, client.dedup, 2)
+	close(release)
+	wg.Wait()
+
+	// 修改和读取一个副本不影响另一个
+	first, second := responses[0], responses[1]
+	first.Header.Set("X-Version", "changed")
+	io.ReadAll(first.Body)
+	first.Body.Close()
+
+	data, _ := io.ReadAll(second.Body)
+	second.Body.Close()
+	if string(data) != "shared" || second.Header.Get("X-Version") != "1" {
+		t.Errorf("第二个副本: %q, X-Version=%q", data, second.Header.Get("X-Version"))
+	}
+	if first.Request == second.Request {
+		t.Error("每个副本应关联各自的请求")
+	}
+}
+
+func TestDedup_Key(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DedupConfig
+		headers  []map[string]string
+		wantHits int32
+	}{
+		{
+			name:     "相同header共享",
+			headers:  []map[string]string{{"Accept": "a"}, {"Accept": "a"}},
+			wantHits: 1,
+		},
+		{
+			name:     "header不同时不共享",
+			headers:  []map[string]string{{"Accept": "a"}, {"Accept": "b"}},
+			wantHits: 2,
+		},
+		{
+			name:     "只比较指定的header",
+			config:   DedupConfig{Headers: []string{"accept"}},
+			headers:  []map[string]string{{"Accept": "a", "X-Trace": "1"}, {"Accept": "a", "X-Trace": "2"}},
+			wantHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			var hits atomic.Int32
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				<-release
+				w.Write([]byte(r.Header.Get("Accept")))
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, Dedup: &tt.config})
+			done := make(chan []dedupResult)
+			go func() { done <- concurrentGets(context.Background(), client, "/test", tt.headers) }()
+			waitWaiters(t, client.dedup, len(tt.headers))
+			close(release)
+
+			for i, r := range <-done {
+				if r.err != nil || r.body != tt.headers[i]["Accept"] {
+					t.Errorf("第 %d 个请求: %q, %v", i, r.body, r.err)
+				}
+			}
+			if hits.Load() != tt.wantHits {
+				t.Errorf("实际请求 %d 次，期望 %d 次", hits.Load(), tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestDedup_NonIdempotent(t *testing.T) {
+	var hits atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Dedup: &DedupConfig{}})
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Post(context.Background(), "/test", map[string]string{"k": "v"}, nil)
+			if err != nil {
+				t.Errorf("请求失败: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if hits.Load() != 3 {
+		t.Errorf("POST 请求 %d 次，期望 3 次", hits.Load())
+	}
+}
+
+// ==================== 取消测试 ====================
+
+func TestDedup_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	serverCanceled := make(chan struct{})
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/wait" {
+			<-r.Context().Done()
+			close(serverCanceled)
+			return
+		}
+		<-release
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1, Dedup: &DedupConfig{}})
+
+	// 第一个调用方取消不影响其他调用方
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := client.Get(ctx, "/test", nil)
+		first <- err
+	}()
+	waitWaiters(t, client.dedup, 1)
+	second := make(chan []dedupResult)
+	go func() { second <- concurrentGets(context.Background(), client, "/test", []map[string]string{nil}) }()
+	waitWaiters(t, client.dedup, 2)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("取消的调用方 error = %v", err)
+	}
+	close(release)
+	if r := (<-second)[0]; r.err != nil || r.body != "ok" {
+		t.Errorf("未取消的调用方: %q, %v", r.body, r.err)
+	}
+
+	// 所有调用方都取消后共享请求被取消
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		_, err := client.Get(ctx, "/wait", nil)
+		first <- err
+	}()
+	waitWaiters(t, client.dedup, 1)
+	cancel()
+	<-first
+	select {
+	case <-serverCanceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("所有调用方取消后共享请求未取消")
+	}
+}
+
+// ==================== 大响应测试 ====================
+
+func TestDedup_LargeBody(t *testing.T) {
+	content := strings.Repeat("x", 100)
+	release := make(chan struct{})
+	var hits atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			<-release
+		}
+		w.Write([]byte(content))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Dedup: &DedupConfig{MaxBodySize: 10}})
+	done := make(chan []dedupResult)
+	go func() { done <- concurrentGets(context.Background(), client, "/large", make([]map[string]string, 3)) }()
+	waitWaiters(t, client.dedup, 3)
+	close(release)
+
+	// 一个等待方读取原响应，其余两个重新请求
+	for i, r := range <-done {
+		if r.err != nil || r.body != content {
+			t.Errorf("第 %d 个请求: %d 字节, %v", i, len(r.body), r.err)
+		}
+	}
+	if hits.Load() != 3 {
+		t.Errorf("实际请求 %d 次，期望 3 次", hits.Load())
+	}
+}
+
+// ==================== 流式响应测试 ====================
+
+func TestDedup_Streaming(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/events" {
+			writeEventStream(w, "data: hello\n\n")
+		} else {
+			w.Write([]byte("part"))
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Dedup: &DedupConfig{}})
+
+	t.Run("单个等待方直接返回原响应", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		resp, err := client.Get(ctx, "/partial", nil)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		defer resp.Body.Close()
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(resp.Body, buf); err != nil || string(buf) != "part" {
+			t.Errorf("读取 %q, %v", buf, err)
+		}
+	})
+
+	t.Run("SSE", func(t *testing.T) {
+		// 多个订阅方各自建立连接，都能及时收到事件
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		var wg sync.WaitGroup
+		for i := range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				start := time.Now()
+				for event, err := range client.SSE(ctx, "/events") {
+					if err != nil || event.Data != "hello" {
+						t.Errorf("第 %d 个订阅方: %+v, %v", i, event, err)
+					}
+					break
+				}
+				if elapsed := time.Since(start); elapsed > time.Second {
+					t.Errorf("第 %d 个订阅方耗时 %v", i, elapsed)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestDedup_StreamCancel(t *testing.T) {
+	canceled := make(chan struct{})
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		writeEventStream(w, "data: hello\n\n")
+		<-r.Context().Done()
+		close(canceled)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Dedup: &DedupConfig{}})
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := client.Get(ctx, "/events", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// 取消调用方 context 后共享请求随之中断
+	cancel()
+	select {
+	case <-canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("取消 context 后请求未中断")
+	}
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Error("期望读取中断的响应体返回错误")
+	}
+}
@@ -0,0 +1,287 @@
+package httpx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksumMismatch 下载内容的校验值与期望不一致
+var ErrChecksumMismatch = errors.New("httpx: 下载内容校验失败")
+
+// DownloadOptions 下载选项
+// 下载大文件时 Config.Timeout 会限制整个下载过程，建议设为负数并使用 BodyReadTimeout
+type DownloadOptions struct {
+	// Progress 进度回调，written 为已写入的字节数（包含续传前已有的部分）
+	// total 为总大小，未知时为 -1
+	Progress func(written, total int64)
+	// Checksum 期望的校验值（十六进制），为空时不校验
+	Checksum string
+	// Hash 校验算法，默认 SHA-256
+	Hash func() hash.Hash
+	// Resume 为 true 时，DownloadFile 在目标文件已有部分内容时使用 Range 请求续传
+	// 下载过程中响应的 ETag 或 Last-Modified 保存在 <文件名>.resume 中，续传时通过 If-Range 发送，
+	// 资源已变化时服务端返回完整内容并重新下载；下载完成后删除该文件
+	Resume bool
+	// RequestOptions 下载请求的参数，如 header、查询参数
+	RequestOptions []RequestOption
+}
+
+// Download 把 path 的响应体流式写入 w，返回写入的字节数
+// 非 2xx 响应返回 *HTTPError；设置了 Checksum 时校验失败返回 ErrChecksumMismatch
+func (c *Client) Download(ctx context.Context, path string, w io.Writer, opts *DownloadOptions) (int64, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	resp, err := c.do(ctx, http.MethodGet, path, nil, newRequestOptions(opts.RequestOptions))
+	if err != nil {
+		return 0, err
+	}
+	if err := CheckResponse(resp); err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	d := newDownloader(opts, 0, resp.ContentLength)
+	n, err := d.copy(w, resp.Body)
+	if err != nil {
+		return n, err
+	}
+	return n, d.check()
+}
+
+// DownloadFile 把 path 的响应体下载到本地文件，返回文件的最终大小
+// 开启 Resume 且文件已存在时，只请求缺少的部分；服务端不支持 Range 时重新下载整个文件
+// 校验失败时删除文件，避免之后在错误的内容上续传
+func (c *Client) DownloadFile(ctx context.Context, path, filename string, opts *DownloadOptions) (int64, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var offset int64
+	var validator string
+	if opts.Resume {
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			return 0, err
+		}
+		validator = loadValidator(filename)
+	}
+
+	o := newRequestOptions(opts.RequestOptions)
+	if offset > 0 {
+		o.headers["Range"] = fmt.Sprintf("bytes=%d-", offset)
+		// 没有保存校验器时（如文件不是由 DownloadFile 下载的）无法确认资源未变化，只能依赖 Checksum
+		if validator != "" {
+			o.headers["If-Range"] = validator
+		}
+	}
+	resp, err := c.do(ctx, http.MethodGet, path, nil, o)
+	if err == nil && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// 与开启 CheckStatus 时一致，统一按 *HTTPError 处理
+		err = CheckResponse(resp)
+	}
+	if err != nil {
+		var httpErr *HTTPError
+		if offset > 0 && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// 本地文件可能已经下载完整
+			if _, size, ok := parseContentRange(httpErr.Header.Get("Content-Range")); ok && size == offset {
+				d := newDownloader(opts, offset, 0)
+				if err := d.hashExisting(file, offset); err != nil {
+					return 0, err
+				}
+				os.Remove(filename + resumeSuffix)
+				return offset, d.verify(filename)
+			}
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return 0, fmt.Errorf("httpx: 续传响应的 Content-Range %q 与本地文件大小 %d 不一致", resp.Header.Get("Content-Range"), offset)
+		}
+		// 不支持 If-Range 的服务端可能返回已变化资源的片段，丢弃已有内容，下次重新下载
+		if etag := resp.Header.Get("ETag"); strings.HasPrefix(validator, `"`) && etag != "" && etag != validator {
+			os.Remove(filename + resumeSuffix)
+			file.Truncate(0)
+			return 0, fmt.Errorf("httpx: 续传期间资源已变化，ETag 由 %s 变为 %s", validator, etag)
+		}
+		total = size
+	default:
+		if err := CheckResponse(resp); err != nil {
+			return 0, err
+		}
+		// 服务端忽略了 Range 或资源已变化，从头开始写
+		offset = 0
+		if err := file.Truncate(0); err != nil {
+			return 0, err
+		}
+	}
+	if opts.Resume {
+		if err := saveValidator(filename, resp); err != nil {
+			return 0, err
+		}
+	}
+
+	d := newDownloader(opts, offset, resp.ContentLength)
+	if d.total < 0 {
+		d.total = total
+	}
+	if err := d.hashExisting(file, offset); err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err := d.copy(file, resp.Body); err != nil {
+		return d.written, err
+	}
+	os.Remove(filename + resumeSuffix)
+	return d.written, d.verify(filename)
+}
+
+// resumeSuffix 保存续传校验器的文件后缀
+const resumeSuffix = ".resume"
+
+// loadValidator 读取上次下载保存的 ETag 或 Last-Modified
+func loadValidator(filename string) string {
+	data, err := os.ReadFile(filename + resumeSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// saveValidator 保存响应的强 ETag 或 Last-Modified，供中断后续传时发送 If-Range
+// If-Range 不能使用弱 ETag，两者都没有时删除已保存的校验器
+func saveValidator(filename string, resp *http.Response) error {
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	if validator == "" {
+		if err := os.Remove(filename + resumeSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(filename+resumeSuffix, []byte(validator), 0o644)
+}
+
+// downloader 写入时统计进度并计算校验值
+type downloader struct {
+	opts    *DownloadOptions
+	hash    hash.Hash
+	written int64
+	total   int64
+}
+
+// newDownloader 创建 downloader，offset 为已有内容的大小
+// remaining 为本次响应的长度，未知时为 -1
+func newDownloader(opts *DownloadOptions, offset, remaining int64) *downloader {
+	d := &downloader{opts: opts, written: offset, total: -1}
+	if remaining >= 0 {
+		d.total = offset + remaining
+	}
+	if opts.Checksum != "" {
+		newHash := opts.Hash
+		if newHash == nil {
+			newHash = sha256.New
+		}
+		d.hash = newHash()
+	}
+	return d
+}
+
+// Write 实现 io.Writer 接口，只用于统计，不保存数据
+func (d *downloader) Write(p []byte) (int, error) {
+	if d.hash != nil {
+		d.hash.Write(p)
+	}
+	d.written += int64(len(p))
+	if d.opts.Progress != nil {
+		d.opts.Progress(d.written, d.total)
+	}
+	return len(p), nil
+}
+
+// copy 把响应体写入 w，同时统计进度和计算校验值
+func (d *downloader) copy(w io.Writer, body io.Reader) (int64, error) {
+	start := d.written
+	_, err := io.Copy(io.MultiWriter(w, d), body)
+	return d.written - start, err
+}
+
+// hashExisting 把续传前已有的内容计入校验值
+func (d *downloader) hashExisting(file *os.File, size int64) error {
+	if d.hash == nil || size == 0 {
+		return nil
+	}
+	_, err := io.Copy(d.hash, io.NewSectionReader(file, 0, size))
+	return err
+}
+
+// check 校验内容
+func (d *downloader) check() error {
+	if d.hash == nil {
+		return nil
+	}
+	if got := hex.EncodeToString(d.hash.Sum(nil)); !strings.EqualFold(got, d.opts.Checksum) {
+		return fmt.Errorf("%w: 期望 %s，实际 %s", ErrChecksumMismatch, d.opts.Checksum, got)
+	}
+	return nil
+}
+
+// verify 校验文件内容，失败时删除文件
+func (d *downloader) verify(filename string) error {
+	if err := d.check(); err != nil {
+		os.Remove(filename)
+		return err
+	}
+	return nil
+}
+
+// parseContentRange 解析 "bytes start-end/size" 或 "bytes */size"，返回起始位置和总大小
+func parseContentRange(value string) (start, size int64, ok bool) {
+	rest, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rangePart, sizePart, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(sizePart, 10, 64)
+	if err != nil {
+		size = -1
+	}
+	if rangePart == "*" {
+		return -1, size, err == nil
+	}
+	startPart, _, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err = strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
@@ -0,0 +1,263 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// downloadContent 下载测试使用的文件内容
+var downloadContent = bytes.Repeat([]byte("0123456789abcdef"), 8<<10)
+
+// downloadChecksum downloadContent 的 SHA-256
+func downloadChecksum() string {
+	sum := sha256.Sum256(downloadContent)
+	return hex.EncodeToString(sum[:])
+}
+
+// md5Sum 计算 MD5
+func md5Sum(data []byte) []byte {
+	sum := md5.Sum(data)
+	return sum[:]
+}
+
+// ==================== Download 测试 ====================
+
+func TestClient_Download(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(downloadContent))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    *DownloadOptions
+		wantErr error
+	}{
+		{name: "默认选项", path: "/data", opts: nil},
+		{name: "校验通过", path: "/data", opts: &DownloadOptions{Checksum: strings.ToUpper(downloadChecksum())}},
+		{name: "自定义哈希", path: "/data", opts: &DownloadOptions{Checksum: hex.EncodeToString(md5Sum(downloadContent)), Hash: md5.New}},
+		{name: "校验失败", path: "/data", opts: &DownloadOptions{Checksum: "deadbeef"}, wantErr: ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := client.Download(context.Background(), tt.path, &buf, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Download() error = %v，期望 %v", err, tt.wantErr)
+			}
+			if n != int64(len(downloadContent)) || !bytes.Equal(buf.Bytes(), downloadContent) {
+				t.Errorf("下载 %d 字节，内容一致 %v", n, bytes.Equal(buf.Bytes(), downloadContent))
+			}
+		})
+	}
+
+	// 非 2xx 响应
+	var httpErr *HTTPError
+	if _, err := client.Download(context.Background(), "/missing", &bytes.Buffer{}, nil); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("期望 404 HTTPError，实际 %v", err)
+	}
+}
+
+func TestClient_DownloadProgress(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(downloadContent))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+
+	var calls int
+	var last, lastTotal int64
+	_, err := client.Download(context.Background(), "/data", &bytes.Buffer{}, &DownloadOptions{
+		Progress: func(written, total int64) {
+			if written < last {
+				t.Errorf("进度倒退: %d -> %d", last, written)
+			}
+			calls++
+			last, lastTotal = written, total
+		},
+	})
+	if err != nil {
+		t.Fatalf("下载失败: %v", err)
+	}
+	size := int64(len(downloadContent))
+	if calls == 0 || last != size || lastTotal != size {
+		t.Errorf("回调 %d 次，最后进度 %d/%d，期望 %d/%d", calls, last, lastTotal, size, size)
+	}
+}
+
+// ==================== DownloadFile 续传测试 ====================
+
+func TestClient_DownloadFileResume(t *testing.T) {
+	const partial = 40000
+	size := int64(len(downloadContent))
+
+	tests := []struct {
+		name         string
+		supportRange bool
+		existing     []byte
+		resume       bool
+		checksum     string
+		checkStatus  bool
+		wantRange    string
+		wantFirst    int64 // 第一次进度回调时已写入的字节数下限
+		wantErr      error
+	}{
+		{name: "续传", supportRange: true, existing: downloadContent[:partial], resume: true, checksum: downloadChecksum(), wantRange: "bytes=40000-", wantFirst: partial},
+		{name: "服务端不支持Range时重新下载", existing: downloadContent[:partial], resume: true, wantRange: "bytes=40000-"},
+		{name: "未开启续传时覆盖已有文件", supportRange: true, existing: []byte("stale content that is longer"), resume: false},
+		{name: "文件已完整", supportRange: true, existing: downloadContent, resume: true, checksum: downloadChecksum(), wantRange: "bytes=131072-"},
+		{name: "开启CheckStatus时文件已完整", supportRange: true, existing: downloadContent, resume: true, checksum: downloadChecksum(), checkStatus: true, wantRange: "bytes=131072-"},
+		{name: "已有内容损坏时校验失败", supportRange: true, existing: bytes.Repeat([]byte("x"), partial), resume: true, checksum: downloadChecksum(), wantRange: "bytes=40000-", wantErr: ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRange string
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				gotRange = r.Header.Get("Range")
+				if !tt.supportRange {
+					w.Write(downloadContent)
+					return
+				}
+				http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(downloadContent))
+			})
+			defer server.Close()
+
+			filename := filepath.Join(t.TempDir(), "data.bin")
+			os.WriteFile(filename, tt.existing, 0o600)
+
+			var first int64 = -1
+			client := NewClient(Config{BaseURL: server.URL, CheckStatus: tt.checkStatus})
+			n, err := client.DownloadFile(context.Background(), "/data", filename, &DownloadOptions{
+				Resume:   tt.resume,
+				Checksum: tt.checksum,
+				Progress: func(written, total int64) {
+					if first < 0 {
+						first = written
+					}
+				},
+			})
+
+			if gotRange != tt.wantRange {
+				t.Errorf("Range = %q，期望 %q", gotRange, tt.wantRange)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("期望 %v，实际 %v", tt.wantErr, err)
+				}
+				if _, statErr := os.Stat(filename); !os.IsNotExist(statErr) {
+					t.Error("校验失败后应删除文件")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("下载失败: %v", err)
+			}
+
+			data, _ := os.ReadFile(filename)
+			if n != size || !bytes.Equal(data, downloadContent) {
+				t.Errorf("返回 %d，文件 %d 字节，内容一致 %v", n, len(data), bytes.Equal(data, downloadContent))
+			}
+			if tt.wantFirst > 0 && first <= tt.wantFirst {
+				t.Errorf("续传的首次进度 %d，期望大于 %d", first, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestClient_DownloadFileIfRange(t *testing.T) {
+	const partial = 40000
+	updated := bytes.Repeat([]byte("fedcba9876543210"), 8<<10)
+
+	tests := []struct {
+		name          string
+		changed       bool
+		ignoreIfRange bool
+		want          []byte
+		wantErr       bool
+	}{
+		{name: "资源未变化时续传", want: downloadContent},
+		{name: "资源变化时重新下载完整内容", changed: true, want: updated},
+		{name: "服务端忽略If-Range时丢弃已有内容", changed: true, ignoreIfRange: true, want: []byte{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var abort, changed atomic.Bool
+			var gotRange, gotIfRange string
+			abort.Store(true)
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				gotRange, gotIfRange = r.Header.Get("Range"), r.Header.Get("If-Range")
+				content, etag := downloadContent, `"v1"`
+				if changed.Load() {
+					content, etag = updated, `"v2"`
+				}
+				w.Header().Set("ETag", etag)
+				if abort.Load() {
+					// 发送一部分后断开连接
+					w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+					w.Write(content[:partial])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				if tt.ignoreIfRange {
+					r.Header.Del("If-Range")
+				}
+				http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+			})
+			defer server.Close()
+
+			filename := filepath.Join(t.TempDir(), "data.bin")
+			client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1})
+			opts := &DownloadOptions{Resume: true}
+			if _, err := client.DownloadFile(context.Background(), "/data", filename, opts); err == nil {
+				t.Fatal("期望下载中断")
+			}
+			if data, _ := os.ReadFile(filename + resumeSuffix); string(data) != `"v1"` {
+				t.Fatalf("保存的校验器 = %q", data)
+			}
+			info, _ := os.Stat(filename)
+
+			abort.Store(false)
+			changed.Store(tt.changed)
+			n, err := client.DownloadFile(context.Background(), "/data", filename, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("续传 error = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if want := fmt.Sprintf("bytes=%d-", info.Size()); gotRange != want || gotIfRange != `"v1"` {
+				t.Errorf("Range = %q、If-Range = %q，期望 %q、\"v1\"", gotRange, gotIfRange, want)
+			}
+			data, _ := os.ReadFile(filename)
+			if n != int64(len(tt.want)) || !bytes.Equal(data, tt.want) {
+				t.Errorf("返回 %d，文件内容与期望一致 %v", n, bytes.Equal(data, tt.want))
+			}
+			if _, err := os.Stat(filename + resumeSuffix); !os.IsNotExist(err) {
+				t.Error("下载完成后应删除校验器文件")
+			}
+		})
+	}
+}
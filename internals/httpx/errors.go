@@ -0,0 +1,95 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxErrorBodySize HTTPError 中保留的响应体上限
+const maxErrorBodySize = 4 << 10
+
+// HTTPError 非 2xx 响应对应的错误，可通过 errors.As 获取
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	Header     http.Header
+	// Body 响应体，超过 4KB 的部分被截断
+	Body []byte
+	// Problem 响应为 application/problem+json 时的解析结果
+	Problem *Problem
+}
+
+// Problem RFC 9457 定义的错误详情
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("httpx: %s %s 返回 %s", e.Method, e.URL, e.Status)
+	if e.Problem != nil {
+		if e.Problem.Detail != "" {
+			return msg + ": " + e.Problem.Detail
+		}
+		if e.Problem.Title != "" {
+			return msg + ": " + e.Problem.Title
+		}
+	}
+	return msg
+}
+
+// CheckResponse 检查响应状态码，非 2xx 时读取并关闭响应体，返回 *HTTPError
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+	}
+	if httpErr.Status == "" {
+		httpErr.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if resp.Request != nil {
+		httpErr.Method = resp.Request.Method
+		httpErr.URL = resp.Request.URL.Redacted()
+	}
+
+	// 读取失败时保留已读到的部分即可，不影响错误本身
+	httpErr.Body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/problem+json" {
+		var problem Problem
+		if json.Unmarshal(httpErr.Body, &problem) == nil {
+			httpErr.Problem = &problem
+		}
+	}
+
+	return httpErr
+}
+
+// checkStatus 内置中间件：非 2xx 响应转换为 *HTTPError
+func checkStatus(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckResponse(resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,157 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ==================== CheckResponse 测试 ====================
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     bool
+		wantProblem *Problem
+		wantMessage string
+	}{
+		{
+			name:    "2xx不返回错误",
+			status:  http.StatusCreated,
+			wantErr: false,
+		},
+		{
+			name:        "普通错误响应",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"error":"not found"}`,
+			wantErr:     true,
+			wantMessage: "httpx: GET http://example.com/users/1 返回 404 Not Found",
+		},
+		{
+			name:        "problem+json响应",
+			status:      http.StatusBadRequest,
+			contentType: "application/problem+json; charset=utf-8",
+			body:        `{"type":"about:blank","title":"参数错误","status":400,"detail":"name 不能为空"}`,
+			wantErr:     true,
+			wantProblem: &Problem{Type: "about:blank", Title: "参数错误", Status: 400, Detail: "name 不能为空"},
+			wantMessage: "httpx: GET http://example.com/users/1 返回 400 Bad Request: name 不能为空",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/users/1", nil)
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{"Content-Type": []string{tt.contentType}},
+				Body:       &mockReadCloser{strings.NewReader(tt.body)},
+				Request:    req,
+			}
+
+			err := CheckResponse(resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("期望 *HTTPError，实际 %T", err)
+			}
+			if httpErr.StatusCode != tt.status || string(httpErr.Body) != tt.body {
+				t.Errorf("StatusCode = %d, Body = %q", httpErr.StatusCode, httpErr.Body)
+			}
+			if err.Error() != tt.wantMessage {
+				t.Errorf("Error() = %q，期望 %q", err.Error(), tt.wantMessage)
+			}
+			if tt.wantProblem == nil && httpErr.Problem != nil {
+				t.Errorf("Problem = %+v，期望 nil", httpErr.Problem)
+			}
+			if tt.wantProblem != nil && (httpErr.Problem == nil || *httpErr.Problem != *tt.wantProblem) {
+				t.Errorf("Problem = %+v，期望 %+v", httpErr.Problem, tt.wantProblem)
+			}
+		})
+	}
+}
+
+func TestCheckResponse_TruncatesBody(t *testing.T) {
+	body := strings.Repeat("x", maxErrorBodySize*2)
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{},
+		Body:       &mockReadCloser{strings.NewReader(body)},
+	}
+
+	var httpErr *HTTPError
+	if !errors.As(CheckResponse(resp), &httpErr) {
+		t.Fatal("期望 *HTTPError")
+	}
+	if len(httpErr.Body) != maxErrorBodySize {
+		t.Errorf("Body 长度 = %d，期望 %d", len(httpErr.Body), maxErrorBodySize)
+	}
+}
+
+// ==================== CheckStatus 模式测试 ====================
+
+func TestClient_CheckStatus(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.Header().Set("X-Request-Id", "abc")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	t.Run("开启后返回HTTPError", func(t *testing.T) {
+		client := NewClient(Config{BaseURL: server.URL, CheckStatus: true})
+
+		resp, err := client.Delete(context.Background(), "/missing", nil)
+		if resp != nil {
+			t.Errorf("期望响应为 nil")
+		}
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("期望 *HTTPError，实际 %v", err)
+		}
+		if httpErr.Method != http.MethodDelete || httpErr.URL != server.URL+"/missing" {
+			t.Errorf("Method = %s, URL = %s", httpErr.Method, httpErr.URL)
+		}
+		if httpErr.Header.Get("X-Request-Id") != "abc" {
+			t.Errorf("Header 未保留")
+		}
+	})
+
+	t.Run("开启后2xx正常返回", func(t *testing.T) {
+		client := NewClient(Config{BaseURL: server.URL, CheckStatus: true})
+
+		resp, err := client.Get(context.Background(), "/ok", nil)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("默认关闭", func(t *testing.T) {
+		client := NewClient(Config{BaseURL: server.URL})
+
+		resp, err := client.Get(context.Background(), "/missing", nil)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("期望状态码 404，实际 %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HedgeConfig 对冲请求配置，只对没有请求体的 GET/HEAD 请求生效
+// 一次尝试超过等待时间仍未返回时再发出一次相同的尝试，采用最先成功的响应并取消其余尝试
+// 对冲发出的尝试与重试共用 MaxRetries 的次数，一个逻辑请求最多发出 MaxRetries+1 次
+type HedgeConfig struct {
+	// Delay 发出下一次尝试前的等待时间
+	Delay time.Duration
+	// Percentile 按最近响应延迟的分位数（0 到 1 之间，如 0.95）确定等待时间
+	// 样本不足 10 个时使用 Delay，Delay 也为 0 时不对冲
+	Percentile float64
+	// Window 计算分位数的最近样本数，默认 100
+	Window int
+	// MaxAttempts 同时进行的最大尝试数，包含第一次，默认 2
+	MaxAttempts int
+}
+
+// minHedgeSamples 按分位数计算等待时间所需的最少样本数
+const minHedgeSamples = 10
+
+// validate 校验对冲配置
+func (c *HedgeConfig) validate() error {
+	if c.Delay <= 0 && c.Percentile <= 0 {
+		return errors.New("httpx: HedgeConfig 需要设置 Delay 或 Percentile")
+	}
+	if c.Percentile < 0 || c.Percentile >= 1 {
+		return errors.New("httpx: HedgeConfig.Percentile 需要在 0 到 1 之间")
+	}
+	return nil
+}
+
+// hedger 记录响应延迟并计算对冲等待时间
+type hedger struct {
+	config  HedgeConfig
+	mu      sync.Mutex
+	samples []time.Duration // 环形缓冲区
+	next    int
+}
+
+// newHedger 创建 hedger 并填充默认配置
+func newHedger(config HedgeConfig) *hedger {
+	if config.Window <= 0 {
+		config.Window = 100
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 2
+	}
+	return &hedger{config: config, samples: make([]time.Duration, 0, config.Window)}
+}
+
+// observe 记录一次响应延迟
+func (h *hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) < h.config.Window {
+		h.samples = append(h.samples, latency)
+		return
+	}
+	h.samples[h.next] = latency
+	h.next = (h.next + 1) % h.config.Window
+}
+
+// delay 返回发出下一次尝试前的等待时间，ok 为 false 表示不对冲
+func (h *hedger) delay() (time.Duration, bool) {
+	if h.config.Percentile > 0 {
+		h.mu.Lock()
+		sorted := slices.Clone(h.samples)
+		h.mu.Unlock()
+		if len(sorted) >= minHedgeSamples {
+			slices.Sort(sorted)
+			i := int(math.Ceil(h.config.Percentile*float64(len(sorted)))) - 1
+			return sorted[max(i, 0)], true
+		}
+	}
+	return h.config.Delay, h.config.Delay > 0
+}
+
+// canHedge 判断请求能否对冲
+func canHedge(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		(req.Body == nil || req.Body == http.NoBody)
+}
+
+// hedgeResult 一次尝试的结果
+type hedgeResult struct {
+	resp    *http.Response
+	err     error
+	attempt int
+	cancel  context.CancelFunc
+}
+
+// hedge 从第 attempt 次尝试开始发出一组对冲尝试，返回采用的结果和发出的尝试数
+// 成功（重试策略认为无需重试）的响应立即采用并取消其余尝试；
+// 全部失败时返回最后一个失败的结果，由重试决定是否继续
+func (c *Client) hedge(ctx context.Context, req *http.Request, attempt int, next RoundTripFunc) (*http.Response, int, error) {
+	maxAttempts := min(c.hedger.config.MaxAttempts, c.retryTimes-attempt+1)
+	results := make(chan hedgeResult, maxAttempts)
+	var cancels []context.CancelFunc
+	launch := func() {
+		n := attempt + len(cancels)
+		attemptCtx, cancel := context.WithCancel(context.WithValue(ctx, attemptKey{}, n))
+		cancels = append(cancels, cancel)
+		start := time.Now()
+		go func() {
+			// 并发的尝试各自使用独立的 header，避免鉴权等中间件互相影响
+			resp, err := next(req.Clone(attemptCtx))
+			if err == nil {
+				c.hedger.observe(time.Since(start))
+			}
+			results <- hedgeResult{resp: resp, err: err, attempt: n, cancel: cancel}
+		}()
+	}
+
+	launch()
+	pending := 1
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	if delay, ok := c.hedger.delay(); ok && maxAttempts > 1 {
+		timer = time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var last *hedgeResult
+	for pending > 0 {
+		select {
+		case <-timeout:
+			launch()
+			pending++
+			if len(cancels) < maxAttempts {
+				delay, _ := c.hedger.delay()
+				timer.Reset(delay)
+			} else {
+				timeout = nil
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil && !c.retryPolicy(r.attempt, r.resp, nil) {
+				// 其余尝试取消后仍需关闭已返回的响应
+				for i, cancel := range cancels {
+					if attempt+i != r.attempt {
+						cancel()
+					}
+				}
+				go discardHedgeResults(results, pending)
+				r.resp.Body = &cancelBody{Reader: r.resp.Body, body: r.resp.Body, cancel: r.cancel}
+				return r.resp, len(cancels), nil
+			}
+			if last != nil {
+				discardHedgeResult(*last)
+			}
+			last = &r
+		}
+	}
+
+	// 最后一个失败的响应可能返回给调用方，关闭响应体时再取消
+	if last.resp != nil {
+		last.resp.Body = &cancelBody{Reader: last.resp.Body, body: last.resp.Body, cancel: last.cancel}
+	} else {
+		last.cancel()
+	}
+	return last.resp, len(cancels), last.err
+}
+
+// discardHedgeResults 接收并丢弃剩余尝试的结果
+func discardHedgeResults(results <-chan hedgeResult, n int) {
+	for range n {
+		discardHedgeResult(<-results)
+	}
+}
+
+// discardHedgeResult 关闭未采用的响应并取消对应的尝试
+func discardHedgeResult(r hedgeResult) {
+	if r.resp != nil {
+		drainBody(r.resp)
+	}
+	r.cancel()
+}
@@ -0,0 +1,222 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// slowFirstServer 前 slow 个请求一直阻塞到被取消，之后的请求立即返回
+// canceled 记录被取消的请求数
+func slowFirstServer(t *testing.T, slow int32) (url string, hits, canceled *atomic.Int32) {
+	t.Helper()
+	hits, canceled = &atomic.Int32{}, &atomic.Int32{}
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= slow {
+			select {
+			case <-r.Context().Done():
+				canceled.Add(1)
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte("fast"))
+	})
+	t.Cleanup(server.Close)
+	return server.URL, hits, canceled
+}
+
+// waitCount 等待计数达到 n
+func waitCount(t *testing.T, counter *atomic.Int32, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for counter.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("计数 %d 未达到 %d", counter.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// ==================== 对冲请求测试 ====================
+
+func TestHedge(t *testing.T) {
+	tests := []struct {
+		name         string
+		slow         int32
+		maxRetries   int
+		maxAttempts  int
+		method       string
+		wantBody     string
+		wantHits     int32
+		wantCanceled int32
+	}{
+		{name: "首次尝试及时返回时不对冲", slow: 0, wantBody: "fast", wantHits: 1},
+		{name: "慢请求触发对冲并取消", slow: 1, wantBody: "fast", wantHits: 2, wantCanceled: 1},
+		{name: "多次对冲", slow: 2, maxAttempts: 3, wantBody: "fast", wantHits: 3, wantCanceled: 2},
+		{name: "最多同时进行MaxAttempts次", slow: 2, maxAttempts: 2, wantHits: 2},
+		{name: "重试次数用尽时不对冲", slow: 1, maxRetries: -1, wantHits: 1},
+		{name: "POST不对冲", slow: 1, method: http.MethodPost, wantHits: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, hits, canceled := slowFirstServer(t, tt.slow)
+			client := NewClient(Config{
+				BaseURL:    url,
+				MaxRetries: tt.maxRetries,
+				Hedge:      &HedgeConfig{Delay: 20 * time.Millisecond, MaxAttempts: tt.maxAttempts},
+			})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			// 阻塞的请求在超时后结束
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+			resp, err := client.Request(ctx, method, "/data", nil, nil)
+
+			var body string
+			if err == nil {
+				data, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				body = string(data)
+			}
+			if body != tt.wantBody {
+				t.Errorf("响应 %q，error = %v，期望 %q", body, err, tt.wantBody)
+			}
+			if tt.wantBody == "" && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error = %v，期望超时", err)
+			}
+			if hits.Load() != tt.wantHits {
+				t.Errorf("请求 %d 次，期望 %d 次", hits.Load(), tt.wantHits)
+			}
+			if tt.wantCanceled > 0 {
+				waitCount(t, canceled, tt.wantCanceled)
+			}
+		})
+	}
+}
+
+func TestHedge_CountsAsRetries(t *testing.T) {
+	var hits atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	var mu sync.Mutex
+	var attempts []int
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+		Hedge:      &HedgeConfig{Delay: 10 * time.Millisecond},
+		Auth: AuthenticatorFunc(func(req *http.Request) error {
+			mu.Lock()
+			attempts = append(attempts, AttemptFromContext(req.Context()))
+			mu.Unlock()
+			return nil
+		}),
+	})
+
+	resp, err := client.Get(context.Background(), "/data", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	// 对冲和重试共发出 MaxRetries+1 次，最后一次的响应可以正常返回
+	if resp.StatusCode != http.StatusServiceUnavailable || hits.Load() != 4 {
+		t.Errorf("状态码 %d、请求 %d 次，期望 503、4 次", resp.StatusCode, hits.Load())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(attempts)
+	if !slices.Equal(attempts, []int{0, 1, 2, 3}) {
+		t.Errorf("尝试序号 = %v", attempts)
+	}
+}
+
+func TestHedge_ContextCancel(t *testing.T) {
+	url, hits, canceled := slowFirstServer(t, 10)
+	client := NewClient(Config{BaseURL: url, Hedge: &HedgeConfig{Delay: 10 * time.Millisecond}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Get(ctx, "/data", nil)
+
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 2*time.Second {
+		t.Errorf("error = %v，耗时 %v", err, time.Since(start))
+	}
+	// ctx 结束后不再发出新的尝试，进行中的尝试全部取消
+	if hits.Load() != 2 {
+		t.Errorf("请求 %d 次，期望 2 次", hits.Load())
+	}
+	waitCount(t, canceled, 2)
+}
+
+// ==================== 等待时间测试 ====================
+
+func TestHedger_Delay(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    HedgeConfig
+		samples   int
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{name: "固定等待", config: HedgeConfig{Delay: 50 * time.Millisecond}, samples: 100, wantDelay: 50 * time.Millisecond, wantOK: true},
+		{name: "按分位数", config: HedgeConfig{Percentile: 0.9}, samples: 100, wantDelay: 90 * time.Millisecond, wantOK: true},
+		{name: "只保留最近的样本", config: HedgeConfig{Percentile: 0.5, Window: 10}, samples: 100, wantDelay: 95 * time.Millisecond, wantOK: true},
+		{name: "样本不足时使用Delay", config: HedgeConfig{Percentile: 0.9, Delay: time.Second}, samples: 5, wantDelay: time.Second, wantOK: true},
+		{name: "样本不足且没有Delay时不对冲", config: HedgeConfig{Percentile: 0.9}, samples: 5, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHedger(tt.config)
+			for i := 1; i <= tt.samples; i++ {
+				h.observe(time.Duration(i) * time.Millisecond)
+			}
+			delay, ok := h.delay()
+			if delay != tt.wantDelay || ok != tt.wantOK {
+				t.Errorf("delay() = %v, %v，期望 %v, %v", delay, ok, tt.wantDelay, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHedgeConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  HedgeConfig
+		wantErr bool
+	}{
+		{name: "固定等待", config: HedgeConfig{Delay: time.Millisecond}},
+		{name: "分位数", config: HedgeConfig{Percentile: 0.95}},
+		{name: "未设置等待时间", config: HedgeConfig{}, wantErr: true},
+		{name: "分位数超出范围", config: HedgeConfig{Percentile: 1.5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Hedge: &tt.config})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v，期望出错 %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package httpx
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"io"
 	"net/http"
 	"time"
@@ -17,81 +16,245 @@ type Client struct {
 	timeout     time.Duration
 	retryTimes  int
 	retryDelay  time.Duration
+	retryPolicy RetryPolicy
+	backoff     Backoff
+	maxDelay    time.Duration
+	middlewares []Middleware
+	checkStatus bool
+	breaker     *circuitBreaker
+	limiter     *rateLimiter
+	auth        Authenticator
+	logger      *requestLogger
+	metrics     *clientMetrics
+	tracer      Tracer
+	timeouts    timeouts
+	total       time.Duration
+	cache       *responseCache
+	dedup       *deduplicator
+	hedger      *hedger
+	compressor  *compressor
+	codec       Codec
+	handler     RoundTripFunc
 }
 
 // Config 客户端配置
 type Config struct {
 	BaseURL    string
-	Timeout    time.Duration
+	Timeout    time.Duration // 单次尝试的超时，包含读取响应体，默认 30 秒，负数表示不限制
 	MaxRetries int
 	RetryDelay time.Duration
 	Headers    map[string]string
+	// RetryPolicy 重试策略，为 nil 时使用 DefaultRetryPolicy
+	RetryPolicy RetryPolicy
+	// Backoff 退避策略，为 nil 时每次固定等待 RetryDelay
+	Backoff Backoff
+	// MaxRetryDelay 单次重试等待的上限，默认 30 秒
+	// 服务端 Retry-After 要求的等待超过该值时不再重试
+	MaxRetryDelay time.Duration
+	// Middlewares 中间件链，按顺序从外到内执行，位于重试之外
+	Middlewares []Middleware
+	// CheckStatus 为 true 时非 2xx 响应返回 *HTTPError，响应体已关闭
+	CheckStatus bool
+	// CircuitBreaker 按 host 熔断的配置，为 nil 时不开启
+	CircuitBreaker *CircuitBreakerConfig
+	// RateLimit 客户端令牌桶限流配置，为 nil 时不限流
+	RateLimit *RateLimitConfig
+	// Auth 鉴权方式，每次尝试发送前调用，为 nil 时不鉴权
+	Auth Authenticator
+	// Logging 请求日志配置，为 nil 时不记录日志
+	Logging *LogConfig
+	// Metrics 指标记录，为 nil 时不记录，可使用 NewMemoryMetrics()
+	// route 标签为 Do 等方法的路径模板，Get、Post、Request 等方法发送的请求为空
+	Metrics Metrics
+	// Tracer 链路追踪，每个逻辑请求一个 span 并注入 traceparent，为 nil 时不追踪
+	Tracer Tracer
+	// Transport 连接池、TLS 和代理配置，为 nil 时使用 http.DefaultTransport
+	Transport *TransportConfig
+	// RoundTripper 自定义底层传输，不能与 Transport 同时设置
+	RoundTripper http.RoundTripper
+	// DialTimeout 建立 TCP 连接的超时，超时返回 ErrDialTimeout
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout TLS 握手的超时，超时返回 ErrTLSHandshakeTimeout
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout 请求发送完毕后等待响应头的超时，超时返回 ErrResponseHeaderTimeout
+	ResponseHeaderTimeout time.Duration
+	// BodyReadTimeout 读取响应体的空闲超时，单次读取超过该时间没有数据返回 ErrBodyReadTimeout
+	// 只要数据持续到达就不会超时，下载大文件时可配合负数的 Timeout 使用
+	BodyReadTimeout time.Duration
+	// TotalTimeout 逻辑请求的总超时，覆盖所有重试、重试等待和读取响应体，超时返回 ErrTotalTimeout
+	TotalTimeout time.Duration
+	// Cache 按 HTTP 缓存语义缓存 GET 响应，为 nil 时不缓存
+	Cache *CacheConfig
+	// Dedup 合并并发的相同 GET/HEAD 请求，为 nil 时不合并
+	Dedup *DedupConfig
+	// Hedge 对冲请求配置，为 nil 时不对冲
+	Hedge *HedgeConfig
+	// Compression 请求体压缩和响应解码配置，为 nil 时只使用 http.Transport 的透明 gzip
+	Compression *CompressionConfig
+	// Codec Post/Put 默认使用的编解码器，为 nil 时使用 JSONCodec
+	Codec Codec
 }
 
-// NewClient 创建新的 HTTP 客户端
+// NewClient 创建新的 HTTP 客户端，配置无效时 panic
+// 需要处理配置错误（如证书文件不存在）时使用 New
 func NewClient(config Config) *Client {
+	c, err := New(config)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// New 校验配置并创建新的 HTTP 客户端
+func New(config Config) (*Client, error) {
+	if err := config.validateTransport(); err != nil {
+		return nil, err
+	}
+	if config.Hedge != nil {
+		if err := config.Hedge.validate(); err != nil {
+			return nil, err
+		}
+	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
 	}
+	if config.Timeout < 0 {
+		config.Timeout = 0
+	}
 	if config.MaxRetries == 0 {
 		config.MaxRetries = 3
 	}
+	// 负数表示不重试
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 100 * time.Millisecond
 	}
+	if config.RetryPolicy == nil {
+		config.RetryPolicy = DefaultRetryPolicy
+	}
+	if config.Backoff == nil {
+		config.Backoff = ConstantBackoff(config.RetryDelay)
+	}
+	if config.MaxRetryDelay == 0 {
+		config.MaxRetryDelay = 30 * time.Second
+	}
+
+	transport := config.RoundTripper
+	if config.Transport != nil {
+		t, err := newTransport(*config.Transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = t
+	}
 
-	return &Client{
+	c := &Client{
 		client: &http.Client{
-			Timeout: config.Timeout,
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+		baseURL:     config.BaseURL,
+		headers:     config.Headers,
+		timeout:     config.Timeout,
+		retryTimes:  config.MaxRetries,
+		retryDelay:  config.RetryDelay,
+		retryPolicy: config.RetryPolicy,
+		backoff:     config.Backoff,
+		maxDelay:    config.MaxRetryDelay,
+		middlewares: config.Middlewares,
+		checkStatus: config.CheckStatus,
+		auth:        config.Auth,
+		tracer:      config.Tracer,
+		timeouts: timeouts{
+			dial:         config.DialTimeout,
+			tlsHandshake: config.TLSHandshakeTimeout,
+			header:       config.ResponseHeaderTimeout,
+			bodyRead:     config.BodyReadTimeout,
 		},
-		baseURL:    config.BaseURL,
-		headers:    config.Headers,
-		timeout:    config.Timeout,
-		retryTimes: config.MaxRetries,
-		retryDelay: config.RetryDelay,
+		total: config.TotalTimeout,
+	}
+	if config.CircuitBreaker != nil {
+		c.breaker = newCircuitBreaker(*config.CircuitBreaker)
+	}
+	if config.RateLimit != nil {
+		c.limiter = newRateLimiter(*config.RateLimit)
+	}
+	if config.Logging != nil {
+		c.logger = newRequestLogger(*config.Logging)
+	}
+	if config.Metrics != nil {
+		c.metrics = &clientMetrics{metrics: config.Metrics}
+	}
+	if config.Cache != nil {
+		c.cache = newResponseCache(*config.Cache)
+	}
+	if config.Dedup != nil {
+		c.dedup = newDeduplicator(*config.Dedup)
 	}
+	if config.Hedge != nil {
+		c.hedger = newHedger(*config.Hedge)
+	}
+	if config.Compression != nil {
+		compressor, err := newCompressor(*config.Compression)
+		if err != nil {
+			return nil, err
+		}
+		c.compressor = compressor
+	}
+	c.codec = config.Codec
+	if c.codec == nil {
+		c.codec = JSONCodec
+	}
+	c.buildHandler()
+	return c, nil
 }
 
 // Request 通用请求方法
 func (c *Client) Request(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
-	url := c.baseURL + path
+	reqBody, closer := detachCloser(body)
+	if closer != nil {
+		// 请求体的所有权与 http.Client.Do 一致：所有尝试结束后关闭
+		defer closer.Close()
+	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	url, err := joinURL(c.baseURL, path)
 	if err != nil {
 		return nil, err
 	}
 
-	// 设置默认 header
-	if c.headers != nil {
-		for k, v := range c.headers {
-			req.Header.Set(k, v)
-		}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if err := setGetBody(req, body); err != nil {
+		return nil, err
 	}
 
-	// 设置请求特定的 header
+	// 设置请求特定的 header，默认 header 由中间件补齐
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
-	// 重试逻辑
-	var resp *http.Response
-	var lastErr error
-	for i := 0; i <= c.retryTimes; i++ {
-		resp, err = c.client.Do(req)
-		if err == nil {
-			break
-		}
-		lastErr = err
-		if i < c.retryTimes {
-			time.Sleep(c.retryDelay)
-		}
-	}
+	return c.handler(req)
+}
 
+// Do 发送请求，path 支持 /users/{id} 形式的路径模板
+// 路径参数、查询参数和 header 通过 RequestOption 指定
+func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, opts ...RequestOption) (*http.Response, error) {
+	return c.do(ctx, method, path, body, newRequestOptions(opts))
+}
+
+// do 展开路径模板和查询参数后发送请求
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, o *requestOptions) (*http.Response, error) {
+	// 指标使用展开前的路径模板作为 route 标签
+	ctx = withRoute(ctx, path)
+	path, err := o.resolvePath(path)
 	if err != nil {
-		return nil, lastErr
+		return nil, err
 	}
-
-	return resp, nil
+	return c.Request(ctx, method, path, body, o.headers)
 }
 
 // Get GET 请求
@@ -99,34 +262,43 @@ func (c *Client) Get(ctx context.Context, path string, headers map[string]string
 	return c.Request(ctx, http.MethodGet, path, nil, headers)
 }
 
-// Post JSON POST 请求
+// Post POST 请求，data 按 Content-Type header 选择编解码器编码
+// 未指定或没有注册对应的编解码器时使用 Config.Codec，Content-Type 设为该编解码器的类型
 func (c *Client) Post(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, error) {
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return nil, err
-	}
+	return c.send(ctx, http.MethodPost, path, data, headers)
+}
+
+// Put PUT 请求，编码方式同 Post
+func (c *Client) Put(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, error) {
+	return c.send(ctx, http.MethodPut, path, data, headers)
+}
 
+// send 编码 data 作为请求体发送
+func (c *Client) send(ctx context.Context, method, path string, data any, headers map[string]string) (*http.Response, error) {
 	if headers == nil {
 		headers = make(map[string]string)
 	}
-	headers["Content-Type"] = "application/json"
 
-	return c.Request(ctx, http.MethodPost, path, bytes.NewReader(jsonData), headers)
-}
+	codec, key := c.codec, "Content-Type"
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == "Content-Type" {
+			key = k
+			break
+		}
+	}
+	if registered, ok := CodecFor(headers[key]); ok {
+		codec = registered
+	} else {
+		// 使用默认编解码器时覆盖调用方的 Content-Type，避免请求体与声明的类型不符
+		headers[key] = codec.ContentType()
+	}
 
-// Put JSON PUT 请求
-func (c *Client) Put(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, error) {
-	jsonData, err := json.Marshal(data)
+	body, err := codec.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
 
-	if headers == nil {
-		headers = make(map[string]string)
-	}
-	headers["Content-Type"] = "application/json"
-
-	return c.Request(ctx, http.MethodPut, path, bytes.NewReader(jsonData), headers)
+	return c.Request(ctx, method, path, bytes.NewReader(body), headers)
 }
 
 // Delete DELETE 请求
@@ -134,7 +306,8 @@ func (c *Client) Delete(ctx context.Context, path string, headers map[string]str
 	return c.Request(ctx, http.MethodDelete, path, nil, headers)
 }
 
-// ParseResponse 解析响应体到指定结构
+// ParseResponse 按 Content-Type 选择编解码器解析响应体到指定结构
+// 没有 Content-Type 或未注册对应的编解码器时按 JSON 解析
 func ParseResponse[T any](resp *http.Response) (T, error) {
 	var result T
 	defer resp.Body.Close()
@@ -144,7 +317,11 @@ func ParseResponse[T any](resp *http.Response) (T, error) {
 		return result, err
 	}
 
-	err = json.Unmarshal(body, &result)
+	codec, ok := CodecFor(resp.Header.Get("Content-Type"))
+	if !ok {
+		codec = JSONCodec
+	}
+	err = codec.Unmarshal(body, &result)
 	return result, err
 }
 
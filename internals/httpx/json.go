@@ -0,0 +1,64 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// jsonOptions 应用请求参数，未指定 Accept 时声明接受 JSON 响应
+func jsonOptions(opts []RequestOption) *requestOptions {
+	o := newRequestOptions(opts)
+	if _, ok := o.headers["Accept"]; !ok {
+		o.headers["Accept"] = "application/json"
+	}
+	return o
+}
+
+// GetJSON 发送 GET 请求并把 JSON 响应解码为 T
+// 非 2xx 响应返回 *HTTPError，响应体总会被关闭
+func GetJSON[T any](ctx context.Context, c *Client, path string, opts ...RequestOption) (T, error) {
+	return decodeJSON[T](c.do(ctx, http.MethodGet, path, nil, jsonOptions(opts)))
+}
+
+// PostJSON 以 JSON 发送 POST 请求并把 JSON 响应解码为 Resp
+func PostJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, opts ...RequestOption) (Resp, error) {
+	return decodeJSON[Resp](c.sendJSON(ctx, http.MethodPost, path, body, jsonOptions(opts)))
+}
+
+// PutJSON 以 JSON 发送 PUT 请求并把 JSON 响应解码为 Resp
+func PutJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, opts ...RequestOption) (Resp, error) {
+	return decodeJSON[Resp](c.sendJSON(ctx, http.MethodPut, path, body, jsonOptions(opts)))
+}
+
+// sendJSON 把 data 编码为 JSON 作为请求体发送
+func (c *Client) sendJSON(ctx context.Context, method, path string, data any, o *requestOptions) (*http.Response, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	o.headers["Content-Type"] = "application/json"
+	return c.do(ctx, method, path, bytes.NewReader(jsonData), o)
+}
+
+// decodeJSON 检查状态码并流式解码响应体
+// 204 或空响应体返回零值
+func decodeJSON[T any](resp *http.Response, err error) (T, error) {
+	var result T
+	if err != nil {
+		return result, err
+	}
+	if err := CheckResponse(resp); err != nil {
+		return result, err
+	}
+	// 读完剩余内容再关闭，连接才能被复用
+	defer drainBody(resp)
+
+	if resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 {
+		return result, nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
@@ -0,0 +1,169 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ==================== 测试辅助类型 ====================
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// closeTracker 记录是否被关闭的响应体
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+// stubClient 返回固定响应的客户端，不发起网络请求
+func stubClient(status int, body io.ReadCloser) *Client {
+	return NewClient(Config{
+		BaseURL: "http://example.com",
+		Middlewares: []Middleware{func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: status, Header: http.Header{}, Body: body, Request: req, ContentLength: -1}, nil
+			}
+		}},
+	})
+}
+
+// ==================== GetJSON 测试 ====================
+
+func TestGetJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		want        user
+		wantErr     bool
+		wantHTTPErr bool
+	}{
+		{
+			name:   "成功解码",
+			status: http.StatusOK,
+			body:   `{"id":1,"name":"alice"}`,
+			want:   user{ID: 1, Name: "alice"},
+		},
+		{
+			name:   "204返回零值",
+			status: http.StatusNoContent,
+			want:   user{},
+		},
+		{
+			name:    "无效JSON",
+			status:  http.StatusOK,
+			body:    `{invalid`,
+			wantErr: true,
+		},
+		{
+			name:        "4xx返回HTTPError",
+			status:      http.StatusNotFound,
+			body:        `{"error":"not found"}`,
+			wantErr:     true,
+			wantHTTPErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept"); got != "application/json" {
+					t.Errorf("Accept = %q，期望 application/json", got)
+				}
+				if got := r.Header.Get("X-Trace"); got != "t1" {
+					t.Errorf("X-Trace = %q，期望 t1", got)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL})
+			got, err := GetJSON[user](context.Background(), client, "/users/1", WithHeader("X-Trace", "t1"))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) != tt.wantHTTPErr {
+				t.Errorf("HTTPError = %v，期望 %v", httpErr, tt.wantHTTPErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GetJSON() = %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJSON_ClosesBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "成功响应", status: http.StatusOK, body: `{"id":1} trailing`},
+		{name: "错误响应", status: http.StatusBadRequest, body: `oops`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeTracker{Reader: strings.NewReader(tt.body)}
+			client := stubClient(tt.status, body)
+
+			GetJSON[user](context.Background(), client, "/users/1")
+			if !body.closed {
+				t.Error("响应体未关闭")
+			}
+		})
+	}
+}
+
+// ==================== PostJSON / PutJSON 测试 ====================
+
+func TestPostJSON(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		var in user
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		in.ID = 42
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(in)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	got, err := PostJSON[user, user](ctx, client, "/users", user{Name: "bob"})
+	if err != nil {
+		t.Fatalf("PostJSON 失败: %v", err)
+	}
+	if got != (user{ID: 42, Name: "bob"}) {
+		t.Errorf("PostJSON() = %+v", got)
+	}
+
+	got, err = PutJSON[user, user](ctx, client, "/users/42", user{Name: "carol"})
+	if err != nil {
+		t.Fatalf("PutJSON 失败: %v", err)
+	}
+	if got != (user{ID: 42, Name: "carol"}) {
+		t.Errorf("PutJSON() = %+v", got)
+	}
+}
@@ -0,0 +1,311 @@
+package httpx
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted 脱敏后的占位值
+const redacted = "[REDACTED]"
+
+// LogConfig 请求日志配置
+type LogConfig struct {
+	// Logger 日志输出，默认 slog.Default()
+	Logger *slog.Logger
+	// LogBodies 为 true 时记录请求体和响应体
+	LogBodies bool
+	// MaxBodySize 记录的请求体、响应体上限，默认 4KB
+	MaxBodySize int
+	// RedactHeaders 需要脱敏的 header，默认 Authorization、Proxy-Authorization、Cookie、Set-Cookie
+	RedactHeaders []string
+	// RedactFields 需要脱敏的 JSON 字段、表单字段和查询参数，不区分大小写
+	// 默认 password、secret、token、access_token、refresh_token、client_secret
+	RedactFields []string
+}
+
+// requestLogger 请求日志记录器
+type requestLogger struct {
+	logger        *slog.Logger
+	logBodies     bool
+	maxBodySize   int
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+	fieldPattern  *regexp.Regexp // 截断后无法解析的 JSON 使用正则脱敏
+}
+
+// newRequestLogger 根据配置创建日志记录器
+func newRequestLogger(config LogConfig) *requestLogger {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 4 << 10
+	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	}
+	if config.RedactFields == nil {
+		config.RedactFields = []string{"password", "secret", "token", "access_token", "refresh_token", "client_secret"}
+	}
+
+	l := &requestLogger{
+		logger:        config.Logger,
+		logBodies:     config.LogBodies,
+		maxBodySize:   config.MaxBodySize,
+		redactHeaders: make(map[string]bool),
+		redactFields:  make(map[string]bool),
+	}
+	for _, h := range config.RedactHeaders {
+		l.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	var names []string
+	for _, f := range config.RedactFields {
+		l.redactFields[strings.ToLower(f)] = true
+		names = append(names, regexp.QuoteMeta(f))
+	}
+	if len(names) > 0 {
+		l.fieldPattern = regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	}
+	return l
+}
+
+// middleware 日志中间件，位于重试和鉴权之内，每次实际发送都会记录
+func (l *requestLogger) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("url", l.redactURL(req.URL)),
+			slog.Int("attempt", AttemptFromContext(ctx)),
+			slog.Any("request_headers", l.headerAttrs(req.Header)),
+		}
+		if l.logBodies {
+			attrs = append(attrs, slog.String("request_body", l.requestBody(req)))
+		}
+
+		start := time.Now()
+		resp, err := next(req)
+		attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+			l.logger.LogAttrs(ctx, slog.LevelError, "http request failed", attrs...)
+			return nil, err
+		}
+
+		attrs = append(attrs,
+			slog.Int("status", resp.StatusCode),
+			slog.Int64("response_size", resp.ContentLength),
+			slog.Any("response_headers", l.headerAttrs(resp.Header)),
+		)
+		level := slog.LevelInfo
+		if resp.StatusCode >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		log := func(body string) {
+			if l.logBodies {
+				attrs = append(attrs, slog.String("response_body", body))
+			}
+			l.logger.LogAttrs(ctx, level, "http request", attrs...)
+		}
+
+		// 响应体在调用方读取时记录，读到结尾或关闭后再输出日志，不阻塞流式响应
+		if l.logBodies && resp.ContentLength != 0 && resp.Header.Get("Content-Encoding") == "" {
+			resp.Body = &loggedBody{
+				ReadCloser: resp.Body,
+				limit:      l.maxBodySize + 1,
+				emit: func(data []byte, eof bool) {
+					body := l.formatBody(resp.Header.Get("Content-Type"), data)
+					// 未读完就关闭时只记录已读取的部分
+					if !eof && len(data) <= l.maxBodySize {
+						body += "...(truncated)"
+					}
+					log(body)
+				},
+			}
+			return resp, nil
+		}
+		log(l.responseBody(resp))
+		return resp, nil
+	}
+}
+
+// headerAttrs 把 header 转换为日志属性，敏感 header 脱敏
+func (l *requestLogger) headerAttrs(header http.Header) slog.Value {
+	attrs := make([]slog.Attr, 0, len(header))
+	for k, vs := range header {
+		value := strings.Join(vs, ", ")
+		if l.redactHeaders[http.CanonicalHeaderKey(k)] {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(k, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// redactURL 对敏感查询参数脱敏
+func (l *requestLogger) redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+	query := u.Query()
+	for k := range query {
+		if l.redactFields[strings.ToLower(k)] {
+			query[k] = []string{redacted}
+		}
+	}
+	clone := *u
+	clone.RawQuery = query.Encode()
+	return clone.Redacted()
+}
+
+// requestBody 读取请求体用于记录，不影响实际发送
+func (l *requestLogger) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	// 压缩后的内容无法阅读，只记录编码
+	if encoding := req.Header.Get("Content-Encoding"); encoding != "" {
+		return "[" + encoding + "]"
+	}
+	if req.GetBody == nil {
+		return "[stream]"
+	}
+	var data []byte
+	err := readBody(req, func(body io.Reader) (err error) {
+		data, err = io.ReadAll(io.LimitReader(body, int64(l.maxBodySize)+1))
+		return err
+	})
+	if err != nil {
+		return "[unreadable]"
+	}
+	return l.formatBody(req.Header.Get("Content-Type"), data)
+}
+
+// responseBody 不需要读取响应体时记录的内容
+func (l *requestLogger) responseBody(resp *http.Response) string {
+	// 压缩后的内容无法阅读，只记录编码
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+		return "[" + encoding + "]"
+	}
+	return ""
+}
+
+// loggedBody 在调用方读取时保留前 limit 字节的响应体，读到结尾、出错或关闭时调用一次 emit
+type loggedBody struct {
+	io.ReadCloser
+	limit int
+	emit  func(data []byte, eof bool)
+
+	mu   sync.Mutex
+	buf  []byte
+	once sync.Once
+}
+
+// Read 读取响应体并保留前缀
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	if room := b.limit - len(b.buf); room > 0 {
+		b.buf = append(b.buf, p[:min(n, room)]...)
+	}
+	b.mu.Unlock()
+	if err != nil {
+		b.done(true)
+	}
+	return n, err
+}
+
+// Close 关闭响应体并输出日志
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done(false)
+	return err
+}
+
+// done 输出一次日志
+func (b *loggedBody) done(eof bool) {
+	b.once.Do(func() {
+		b.mu.Lock()
+		data := b.buf
+		b.mu.Unlock()
+		b.emit(data, eof)
+	})
+}
+
+// formatBody 对 JSON 和表单内容脱敏，超过上限的部分截断
+// data 最多比上限多读 1 字节，用于判断是否截断
+func (l *requestLogger) formatBody(contentType string, data []byte) string {
+	truncated := len(data) > l.maxBodySize
+	if truncated {
+		data = data[:l.maxBodySize]
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		data = l.redactJSON(data)
+	case mediaType == "application/x-www-form-urlencoded":
+		data = l.redactForm(data)
+	}
+	if truncated {
+		return string(data) + "...(truncated)"
+	}
+	return string(data)
+}
+
+// redactJSON 对 JSON 中的敏感字段脱敏
+func (l *requestLogger) redactJSON(data []byte) []byte {
+	var v any
+	if err := json.Unmarshal(data, &v); err == nil {
+		if out, err := json.Marshal(l.redactValue(v)); err == nil {
+			return out
+		}
+	}
+	if l.fieldPattern == nil {
+		return data
+	}
+	return l.fieldPattern.ReplaceAll(data, []byte(`${1}"`+redacted+`"`))
+}
+
+// redactForm 对表单中的敏感字段脱敏，保留字段顺序，截断的表单也能处理
+func (l *requestLogger) redactForm(data []byte) []byte {
+	pairs := strings.Split(string(data), "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if l.redactFields[strings.ToLower(name)] {
+			pairs[i] = key + "=" + redacted
+		}
+	}
+	return []byte(strings.Join(pairs, "&"))
+}
+
+// redactValue 递归替换敏感字段的值
+func (l *requestLogger) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if l.redactFields[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = l.redactValue(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = l.redactValue(item)
+		}
+	}
+	return v
+}
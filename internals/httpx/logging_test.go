@@ -0,0 +1,303 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// logEntry 解析后的一条日志
+type logEntry struct {
+	Level           string            `json:"level"`
+	Msg             string            `json:"msg"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	Status          int               `json:"status"`
+	Attempt         int               `json:"attempt"`
+	Error           string            `json:"error"`
+	RequestHeaders  map[string]string `json:"request_headers"`
+	ResponseHeaders map[string]string `json:"response_headers"`
+	RequestBody     string            `json:"request_body"`
+	ResponseBody    string            `json:"response_body"`
+}
+
+// parseLogs 解析 JSON 格式的日志输出
+func parseLogs(t *testing.T, buf *bytes.Buffer) []logEntry {
+	t.Helper()
+
+	var entries []logEntry
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry logEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("解析日志失败: %v: %s", err, line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ==================== 请求日志测试 ====================
+
+func TestLogging(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"access_token":"t-123","profile":{"password":"x"}}`))
+	})
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Auth:    BearerToken("secret-token"),
+		Logging: &LogConfig{
+			Logger:    slog.New(slog.NewJSONHandler(&buf, nil)),
+			LogBodies: true,
+		},
+	})
+
+	resp, err := client.Post(context.Background(), "/login?token=q1&page=2", map[string]string{"user": "alice", "password": "p@ss"}, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	// 记录日志后响应体仍可完整读取
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "t-123") {
+		t.Errorf("响应体 = %q，期望保留原始内容", body)
+	}
+
+	entries := parseLogs(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("期望 1 条日志，实际 %d 条", len(entries))
+	}
+	entry := entries[0]
+
+	if entry.Level != "INFO" || entry.Method != http.MethodPost || entry.Status != http.StatusOK {
+		t.Errorf("level = %s, method = %s, status = %d", entry.Level, entry.Method, entry.Status)
+	}
+	if strings.Contains(entry.URL, "q1") || !strings.Contains(entry.URL, "page=2") {
+		t.Errorf("URL 未正确脱敏: %s", entry.URL)
+	}
+	if got := entry.RequestHeaders["Authorization"]; got != redacted {
+		t.Errorf("Authorization = %q，期望脱敏", got)
+	}
+	if got := entry.ResponseHeaders["Set-Cookie"]; got != redacted {
+		t.Errorf("Set-Cookie = %q，期望脱敏", got)
+	}
+	if strings.Contains(entry.RequestBody, "p@ss") || !strings.Contains(entry.RequestBody, "alice") {
+		t.Errorf("请求体未正确脱敏: %s", entry.RequestBody)
+	}
+	if strings.Contains(entry.ResponseBody, "t-123") || strings.Contains(entry.ResponseBody, `"x"`) {
+		t.Errorf("响应体未正确脱敏: %s", entry.ResponseBody)
+	}
+}
+
+func TestLogging_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		RetryDelay: 1,
+		Logging:    &LogConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil))},
+	})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	// 每次尝试各记录一条，失败的尝试为 WARN
+	entries := parseLogs(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("期望 3 条日志，实际 %d 条", len(entries))
+	}
+	for i, entry := range entries {
+		wantLevel := "WARN"
+		if i == 2 {
+			wantLevel = "INFO"
+		}
+		if entry.Attempt != i || entry.Level != wantLevel {
+			t.Errorf("第 %d 条日志 attempt = %d, level = %s，期望 %d, %s", i, entry.Attempt, entry.Level, i, wantLevel)
+		}
+		if entry.RequestBody != "" || entry.ResponseBody != "" {
+			t.Errorf("未开启 LogBodies 时不应记录请求体和响应体")
+		}
+	}
+}
+
+func TestLogging_Error(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewClient(Config{
+		BaseURL:    "http://127.0.0.1:0",
+		MaxRetries: -1,
+		Logging:    &LogConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil))},
+	})
+
+	if _, err := client.Get(context.Background(), "/test", nil); err == nil {
+		t.Fatal("期望返回错误")
+	}
+
+	entries := parseLogs(t, &buf)
+	if len(entries) != 1 || entries[0].Level != "ERROR" || entries[0].Error == "" {
+		t.Errorf("期望 1 条带 error 的 ERROR 日志，实际 %+v", entries)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	l := newRequestLogger(LogConfig{RedactFields: []string{"password", "Token"}})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "嵌套字段",
+			input: `{"user":"a","items":[{"token":"t1"}],"PASSWORD":"p"}`,
+			want:  `{"PASSWORD":"[REDACTED]","items":[{"token":"[REDACTED]"}],"user":"a"}`,
+		},
+		{
+			name:  "截断的JSON",
+			input: `{"user":"a","password":"p1","token":12345,"note":"abc`,
+			want:  `{"user":"a","password":"[REDACTED]","token":"[REDACTED]","note":"abc`,
+		},
+		{
+			name:  "非对象",
+			input: `[1,2,3]`,
+			want:  `[1,2,3]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(l.redactJSON([]byte(tt.input))); got != tt.want {
+				t.Errorf("redactJSON() = %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogging_TruncatedBody(t *testing.T) {
+	payload := strings.Repeat("a", 100)
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LogConfig{
+			Logger:      slog.New(slog.NewJSONHandler(&buf, nil)),
+			LogBodies:   true,
+			MaxBodySize: 10,
+		},
+	})
+
+	resp, err := client.Request(context.Background(), http.MethodPost, "/echo", strings.NewReader(payload), nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != payload {
+		t.Errorf("回显的请求体长度 %d，期望 %d", len(body), len(payload))
+	}
+
+	entries := parseLogs(t, &buf)
+	want := strings.Repeat("a", 10) + "...(truncated)"
+	if len(entries) != 1 || entries[0].RequestBody != want || entries[0].ResponseBody != want {
+		t.Errorf("期望请求体和响应体截断为 %q，实际 %+v", want, entries)
+	}
+}
+
+func TestLogging_StreamingBody(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		writeEventStream(w, "data: hello\n\n")
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LogConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil)), LogBodies: true},
+	})
+
+	// 记录响应体不能等到读满 MaxBodySize 才返回
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	for event, err := range client.SSE(ctx, "/events") {
+		if err != nil || event.Data != "hello" {
+			t.Fatalf("事件 = %+v, %v", event, err)
+		}
+		break
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("第一个事件耗时 %v", elapsed)
+	}
+
+	// 关闭响应体后输出日志，只包含已读取的部分
+	entries := parseLogs(t, &buf)
+	if len(entries) != 1 || entries[0].ResponseBody != "data: hello\n\n...(truncated)" {
+		t.Errorf("日志 = %+v", entries)
+	}
+}
+
+func TestLogging_FormBody(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=t-123&token_type=bearer"))
+	})
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LogConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil)), LogBodies: true},
+	})
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_secret": {"s-456"}}
+	resp, err := client.PostForm(context.Background(), "/token", form)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	entries := parseLogs(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("期望 1 条日志，实际 %d 条", len(entries))
+	}
+	if want := "client_secret=[REDACTED]&grant_type=client_credentials"; entries[0].RequestBody != want {
+		t.Errorf("请求体 = %q，期望 %q", entries[0].RequestBody, want)
+	}
+	if want := "access_token=[REDACTED]&token_type=bearer"; entries[0].ResponseBody != want {
+		t.Errorf("响应体 = %q，期望 %q", entries[0].ResponseBody, want)
+	}
+}
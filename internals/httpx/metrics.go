@@ -0,0 +1,392 @@
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 客户端记录的指标名称
+const (
+	MetricRequestsTotal    = "httpx_requests_total"           // 逻辑请求数（计数器）
+	MetricRequestDuration  = "httpx_request_duration_seconds" // 逻辑请求耗时，包含重试（直方图）
+	MetricRetriesTotal     = "httpx_retries_total"            // 重试次数（计数器）
+	MetricRequestsInFlight = "httpx_requests_in_flight"       // 进行中的请求数（仪表盘）
+	MetricResponseSize     = "httpx_response_size_bytes"      // 响应体大小（直方图）
+)
+
+// metricHelp 导出 Prometheus 文本格式时使用的说明
+var metricHelp = map[string]string{
+	MetricRequestsTotal:    "Total number of logical HTTP requests.",
+	MetricRequestDuration:  "Latency of logical HTTP requests including retries.",
+	MetricRetriesTotal:     "Total number of retry attempts.",
+	MetricRequestsInFlight: "Number of HTTP requests currently in flight.",
+	MetricResponseSize:     "Size of HTTP response bodies in bytes.",
+}
+
+var (
+	// DefaultDurationBuckets 耗时直方图的默认分桶（秒）
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets 响应大小直方图的默认分桶（字节）
+	DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// Labels 指标标签
+type Labels map[string]string
+
+// Metrics 指标记录接口，可对接 Prometheus 等监控系统
+// 实现需要支持并发调用
+type Metrics interface {
+	// Add 计数器增加 delta
+	Add(name string, labels Labels, delta float64)
+	// AddGauge 仪表盘增加 delta，delta 可为负数
+	AddGauge(name string, labels Labels, delta float64)
+	// Observe 直方图记录一个观测值
+	Observe(name string, labels Labels, value float64)
+}
+
+// ==================== 客户端指标中间件 ====================
+
+// routeKey 上下文中保存路由模板的 key
+type routeKey struct{}
+
+// withRoute 在上下文中记录路由模板，用作指标标签，避免路径参数造成标签爆炸
+func withRoute(ctx context.Context, template string) context.Context {
+	template, _, _ = strings.Cut(template, "?")
+	return context.WithValue(ctx, routeKey{}, template)
+}
+
+// routeOf 返回请求的路由模板，只有 Do 及基于它的 GetJSON、DownloadFile 等方法会记录模板
+// 通过 Get、Post、Request 等方法发送的请求返回空字符串，不使用原始路径，避免每个 ID 产生一个标签值
+func routeOf(req *http.Request) string {
+	route, _ := req.Context().Value(routeKey{}).(string)
+	return route
+}
+
+// statusClass 返回状态码类别，如 2xx；请求出错时为 error
+func statusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
+
+// clientMetrics 客户端指标中间件
+type clientMetrics struct {
+	metrics Metrics
+}
+
+// labels 返回请求的基础标签
+func (m *clientMetrics) labels(req *http.Request) Labels {
+	return Labels{
+		"method": req.Method,
+		"host":   req.URL.Host,
+		"route":  routeOf(req),
+	}
+}
+
+// request 逻辑请求的指标，位于重试之外
+func (m *clientMetrics) request(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		labels := m.labels(req)
+		m.metrics.AddGauge(MetricRequestsInFlight, labels, 1)
+		defer m.metrics.AddGauge(MetricRequestsInFlight, labels, -1)
+
+		start := time.Now()
+		resp, err := next(req)
+
+		result := Labels{"status_class": statusClass(resp, err)}
+		for k, v := range labels {
+			result[k] = v
+		}
+		m.metrics.Add(MetricRequestsTotal, result, 1)
+		m.metrics.Observe(MetricRequestDuration, result, time.Since(start).Seconds())
+		if err == nil {
+			m.observeSize(resp, result)
+		}
+		return resp, err
+	}
+}
+
+// attempt 重试次数的指标，位于重试之内
+func (m *clientMetrics) attempt(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if AttemptFromContext(req.Context()) > 0 {
+			m.metrics.Add(MetricRetriesTotal, m.labels(req), 1)
+		}
+		return next(req)
+	}
+}
+
+// observeSize 记录响应体大小；长度未知时在响应体关闭时按实际读取的字节数记录
+func (m *clientMetrics) observeSize(resp *http.Response, labels Labels) {
+	if resp.ContentLength >= 0 {
+		m.metrics.Observe(MetricResponseSize, labels, float64(resp.ContentLength))
+		return
+	}
+	resp.Body = &countingBody{
+		ReadCloser: resp.Body,
+		observe: func(n int64) {
+			m.metrics.Observe(MetricResponseSize, labels, float64(n))
+		},
+	}
+}
+
+// countingBody 统计读取字节数的响应体
+type countingBody struct {
+	io.ReadCloser
+	n       int64
+	once    sync.Once
+	observe func(n int64)
+}
+
+// Read 实现 io.Reader 接口
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// Close 关闭响应体并记录大小
+func (b *countingBody) Close() error {
+	b.once.Do(func() { b.observe(b.n) })
+	return b.ReadCloser.Close()
+}
+
+// ==================== 内存指标 ====================
+
+// series 一组标签对应的指标值
+type series struct {
+	labels  Labels
+	value   float64
+	buckets []uint64 // 直方图各分桶的计数（非累计）
+	sum     float64
+	count   uint64
+}
+
+// metricFamily 同名指标
+type metricFamily struct {
+	kind   string // counter、gauge 或 histogram
+	bounds []float64
+	series map[string]*series
+}
+
+// MemoryMetrics 内存中的指标实现，可导出为 Prometheus 文本格式
+type MemoryMetrics struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+	buckets  map[string][]float64
+}
+
+// NewMemoryMetrics 创建内存指标，直方图使用默认分桶
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		families: make(map[string]*metricFamily),
+		buckets: map[string][]float64{
+			MetricResponseSize: DefaultSizeBuckets,
+		},
+	}
+}
+
+// SetBuckets 设置直方图分桶的上界，需要在首次记录前调用
+// 未设置的直方图使用 DefaultDurationBuckets
+func (m *MemoryMetrics) SetBuckets(name string, bounds []float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+	m.buckets[name] = bounds
+}
+
+// Add 实现 Metrics 接口
+func (m *MemoryMetrics) Add(name string, labels Labels, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.series(name, "counter", labels).value += delta
+}
+
+// AddGauge 实现 Metrics 接口
+func (m *MemoryMetrics) AddGauge(name string, labels Labels, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.series(name, "gauge", labels).value += delta
+}
+
+// Observe 实现 Metrics 接口
+func (m *MemoryMetrics) Observe(name string, labels Labels, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.series(name, "histogram", labels)
+	bounds := m.families[name].bounds
+	s.buckets[sort.SearchFloat64s(bounds, value)]++
+	s.sum += value
+	s.count++
+}
+
+// Value 返回计数器或仪表盘的当前值，直方图返回观测次数
+func (m *MemoryMetrics) Value(name string, labels Labels) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	family, ok := m.families[name]
+	if !ok {
+		return 0
+	}
+	s, ok := family.series[labelKey(labels)]
+	if !ok {
+		return 0
+	}
+	if family.kind == "histogram" {
+		return float64(s.count)
+	}
+	return s.value
+}
+
+// series 获取或创建一组标签的指标，调用方需持有锁
+func (m *MemoryMetrics) series(name, kind string, labels Labels) *series {
+	family, ok := m.families[name]
+	if !ok {
+		family = &metricFamily{kind: kind, series: make(map[string]*series)}
+		if kind == "histogram" {
+			family.bounds = DefaultDurationBuckets
+			if bounds, ok := m.buckets[name]; ok {
+				family.bounds = bounds
+			}
+		}
+		m.families[name] = family
+	}
+
+	key := labelKey(labels)
+	s, ok := family.series[key]
+	if !ok {
+		s = &series{labels: make(Labels, len(labels))}
+		for k, v := range labels {
+			s.labels[k] = v
+		}
+		if kind == "histogram" {
+			// 最后一个分桶对应 +Inf
+			s.buckets = make([]uint64, len(family.bounds)+1)
+		}
+		family.series[key] = s
+	}
+	return s
+}
+
+// WritePrometheus 以 Prometheus 文本格式导出所有指标
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	names := make([]string, 0, len(m.families))
+	for name := range m.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := m.families[name]
+		if help, ok := metricHelp[name]; ok {
+			fmt.Fprintf(bw, "# HELP %s %s\n", name, help)
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, family.kind)
+
+		keys := make([]string, 0, len(family.series))
+		for key := range family.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := family.series[key]
+			if family.kind != "histogram" {
+				fmt.Fprintf(bw, "%s%s %s\n", name, formatLabels(s.labels, "", 0), formatFloat(s.value))
+				continue
+			}
+			var cumulative uint64
+			for i, n := range s.buckets {
+				cumulative += n
+				le := math.Inf(1)
+				if i < len(family.bounds) {
+					le = family.bounds[i]
+				}
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(s.labels, "le", le), cumulative)
+			}
+			fmt.Fprintf(bw, "%s_sum%s %s\n", name, formatLabels(s.labels, "", 0), formatFloat(s.sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", name, formatLabels(s.labels, "", 0), s.count)
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP 实现 http.Handler 接口，可直接挂载为 /metrics
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+// labelKey 把标签按名称排序后拼接，作为 series 的唯一标识
+func labelKey(labels Labels) string {
+	return formatLabels(labels, "", 0)
+}
+
+// formatLabels 格式化为 {k="v",...}，extra 不为空时追加该标签（用于直方图的 le）
+func formatLabels(labels Labels, extra string, extraValue float64) string {
+	if len(labels) == 0 && extra == "" {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, k, escapeLabelValue(labels[k]))
+	}
+	if extra != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extra, formatFloat(extraValue))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelEscaper 转义标签值中的反斜杠、双引号和换行
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue 转义标签值，调用方负责加引号
+func escapeLabelValue(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// formatFloat 按 Prometheus 文本格式输出浮点数
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
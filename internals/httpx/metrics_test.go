@@ -0,0 +1,200 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 客户端指标测试 ====================
+
+func TestClient_Metrics(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/2" && attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("hello"))
+	})
+	defer server.Close()
+
+	metrics := NewMemoryMetrics()
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		RetryDelay: 1,
+		Metrics:    metrics,
+	})
+
+	ctx := context.Background()
+	for _, id := range []int{1, 2} {
+		resp, err := client.Do(ctx, http.MethodGet, "/users/{id}?verbose=1", nil, WithPathParam("id", id))
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	// 不经过 Do 的请求没有路由模板
+	resp, err := client.Get(ctx, "/users/3", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	u, _ := url.Parse(server.URL)
+	base := Labels{"method": http.MethodGet, "host": u.Host, "route": "/users/{id}"}
+	ok := Labels{"method": http.MethodGet, "host": u.Host, "route": "/users/{id}", "status_class": "2xx"}
+	noRoute := Labels{"method": http.MethodGet, "host": u.Host, "route": "", "status_class": "2xx"}
+	rawPath := Labels{"method": http.MethodGet, "host": u.Host, "route": "/users/3", "status_class": "2xx"}
+
+	tests := []struct {
+		name   string
+		metric string
+		labels Labels
+		want   float64
+	}{
+		{name: "请求数按路由模板聚合", metric: MetricRequestsTotal, labels: ok, want: 2},
+		{name: "重试次数", metric: MetricRetriesTotal, labels: base, want: 2},
+		{name: "请求结束后无进行中请求", metric: MetricRequestsInFlight, labels: base, want: 0},
+		{name: "耗时观测次数", metric: MetricRequestDuration, labels: ok, want: 2},
+		{name: "响应大小观测次数", metric: MetricResponseSize, labels: ok, want: 2},
+		{name: "没有模板时route为空", metric: MetricRequestsTotal, labels: noRoute, want: 1},
+		{name: "不使用原始路径作为route", metric: MetricRequestsTotal, labels: rawPath, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metrics.Value(tt.metric, tt.labels); got != tt.want {
+				t.Errorf("%s = %v，期望 %v", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_MetricsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	metrics := NewMemoryMetrics()
+	client := NewClient(Config{BaseURL: server.URL, Metrics: metrics})
+
+	u, _ := url.Parse(server.URL)
+	labels := Labels{"method": http.MethodGet, "host": u.Host, "route": ""}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := client.Get(context.Background(), "/slow", nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// 等待请求到达服务端
+	for metrics.Value(MetricRequestsInFlight, labels) != 1 {
+		select {
+		case <-done:
+			t.Fatal("请求提前结束")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	close(release)
+	<-done
+
+	if got := metrics.Value(MetricRequestsInFlight, labels); got != 0 {
+		t.Errorf("请求结束后 in-flight = %v，期望 0", got)
+	}
+}
+
+func TestClient_MetricsError(t *testing.T) {
+	metrics := NewMemoryMetrics()
+	client := NewClient(Config{BaseURL: "http://127.0.0.1:0", MaxRetries: -1, Metrics: metrics})
+
+	if _, err := client.Get(context.Background(), "/test", nil); err == nil {
+		t.Fatal("期望返回错误")
+	}
+
+	labels := Labels{"method": http.MethodGet, "host": "127.0.0.1:0", "route": "", "status_class": "error"}
+	if got := metrics.Value(MetricRequestsTotal, labels); got != 1 {
+		t.Errorf("出错请求数 = %v，期望 1", got)
+	}
+}
+
+func TestClient_MetricsChunkedSize(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 多次 Flush 使响应使用分块传输，长度未知
+		w.Write([]byte("abc"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("defg"))
+	})
+	defer server.Close()
+
+	metrics := NewMemoryMetrics()
+	metrics.SetBuckets(MetricResponseSize, []float64{5, 10})
+	client := NewClient(Config{BaseURL: server.URL, Metrics: metrics})
+
+	resp, err := client.Get(context.Background(), "/stream", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	var out strings.Builder
+	metrics.WritePrometheus(&out)
+	if !strings.Contains(out.String(), `httpx_response_size_bytes_sum{host=`) ||
+		!strings.Contains(out.String(), `status_class="2xx"} 7`) {
+		t.Errorf("分块响应大小应在关闭时按实际字节数记录:\n%s", out.String())
+	}
+}
+
+// ==================== Prometheus 导出测试 ====================
+
+func TestMemoryMetrics_WritePrometheus(t *testing.T) {
+	metrics := NewMemoryMetrics()
+	metrics.SetBuckets("latency_seconds", []float64{1, 0.1})
+	metrics.Add("jobs_total", Labels{"queue": `a"b\c`}, 2)
+	metrics.Add("jobs_total", Labels{"queue": `a"b\c`}, 1)
+	metrics.AddGauge("workers", nil, 3)
+	metrics.Observe("latency_seconds", Labels{"op": "x"}, 0.05)
+	metrics.Observe("latency_seconds", Labels{"op": "x"}, 0.1)
+	metrics.Observe("latency_seconds", Labels{"op": "x"}, 5)
+
+	want := `# TYPE jobs_total counter
+jobs_total{queue="a\"b\\c"} 3
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="x",le="0.1"} 2
+latency_seconds_bucket{op="x",le="1"} 2
+latency_seconds_bucket{op="x",le="+Inf"} 3
+latency_seconds_sum{op="x"} 5.15
+latency_seconds_count{op="x"} 3
+# TYPE workers gauge
+workers 3
+`
+
+	var out strings.Builder
+	if err := metrics.WritePrometheus(&out); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("导出结果:\n%s\n期望:\n%s", out.String(), want)
+	}
+
+	// 作为 /metrics 处理器使用
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") || rec.Body.String() != want {
+		t.Errorf("ServeHTTP 输出与 WritePrometheus 不一致")
+	}
+}
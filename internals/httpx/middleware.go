@@ -0,0 +1,101 @@
+package httpx
+
+import (
+	"net/http"
+	"slices"
+)
+
+// RoundTripFunc 执行一次请求并返回响应
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware 中间件，包装下一个 RoundTripFunc
+// 可用于鉴权、日志、指标、链路追踪、请求签名等
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Chain 按顺序组合中间件，第一个中间件位于最外层
+func Chain(final RoundTripFunc, middlewares ...Middleware) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		final = middlewares[i](final)
+	}
+	return final
+}
+
+// With 返回追加了中间件的客户端副本，原客户端不受影响
+// 适合为单次调用临时添加中间件：c.With(mw).Get(ctx, path, nil)
+func (c *Client) With(middlewares ...Middleware) *Client {
+	clone := *c
+	clone.middlewares = append(slices.Clip(c.middlewares), middlewares...)
+	clone.buildHandler()
+	return &clone
+}
+
+// buildHandler 组装完整的请求处理链
+// 顺序：状态码检查 -> 默认 header -> 自定义中间件 -> 缓存 -> 合并请求 -> 压缩 -> 追踪 -> 指标 -> 总超时 -> 重试 -> 重试计数 -> 尝试事件 -> 鉴权 -> 日志 -> 限流 -> 熔断 -> 分阶段超时 -> 发送请求
+func (c *Client) buildHandler() {
+	var middlewares []Middleware
+	if c.checkStatus {
+		middlewares = append(middlewares, checkStatus)
+	}
+	middlewares = append(middlewares, defaultHeaders(c.headers))
+	middlewares = append(middlewares, c.middlewares...)
+	if c.cache != nil {
+		middlewares = append(middlewares, c.cache.middleware)
+	}
+	if c.dedup != nil {
+		middlewares = append(middlewares, c.dedup.middleware)
+	}
+	if c.compressor != nil {
+		middlewares = append(middlewares, c.compressor.middleware)
+	}
+	if c.tracer != nil {
+		middlewares = append(middlewares, traceRequest(c.tracer))
+	}
+	if c.metrics != nil {
+		middlewares = append(middlewares, c.metrics.request)
+	}
+	if c.total > 0 {
+		middlewares = append(middlewares, totalTimeout(c.total))
+	}
+	middlewares = append(middlewares, c.retry)
+	if c.metrics != nil {
+		middlewares = append(middlewares, c.metrics.attempt)
+	}
+	if c.tracer != nil {
+		middlewares = append(middlewares, traceAttempt)
+	}
+	if c.auth != nil {
+		middlewares = append(middlewares, authenticate(c.auth))
+	}
+	if c.logger != nil {
+		middlewares = append(middlewares, c.logger.middleware)
+	}
+	if c.limiter != nil {
+		middlewares = append(middlewares, c.limiter.middleware)
+	}
+	if c.breaker != nil {
+		middlewares = append(middlewares, c.breaker.middleware)
+	}
+	if c.timeouts.enabled() {
+		middlewares = append(middlewares, phaseTimeouts(c.timeouts))
+	}
+	c.handler = Chain(c.client.Do, middlewares...)
+}
+
+// defaultHeaders 内置中间件：补齐客户端默认 header，请求中已设置的 header 优先
+func defaultHeaders(headers map[string]string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			for k, v := range headers {
+				if req.Header.Get(k) == "" {
+					req.Header.Set(k, v)
+				}
+			}
+			return next(req)
+		}
+	}
+}
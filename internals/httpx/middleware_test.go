@@ -0,0 +1,173 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// recordMiddleware 记录执行顺序的中间件
+func recordMiddleware(name string, order *[]string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*order = append(*order, name+":before")
+			resp, err := next(req)
+			*order = append(*order, name+":after")
+			return resp, err
+		}
+	}
+}
+
+// ==================== 中间件链测试 ====================
+
+func TestClient_MiddlewareOrder(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	var order []string
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Middlewares: []Middleware{
+			recordMiddleware("a", &order),
+			recordMiddleware("b", &order),
+		},
+	})
+
+	resp, err := client.With(recordMiddleware("call", &order)).Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	want := []string{"a:before", "b:before", "call:before", "call:after", "b:after", "a:after"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("执行顺序 = %v，期望 %v", order, want)
+	}
+}
+
+func TestClient_MiddlewareSeesHeaders(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Signature"); got != "signed:Bearer token" {
+			t.Errorf("X-Signature = %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	// 签名中间件依赖已合并的默认 header
+	sign := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Signature", "signed:"+req.Header.Get("Authorization"))
+			return next(req)
+		}
+	}
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		Middlewares: []Middleware{sign},
+	})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClient_MiddlewareShortCircuit(t *testing.T) {
+	// 中间件可以不调用 next 直接返回响应
+	stub := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusTeapot,
+				Body:       io.NopCloser(strings.NewReader("stub")),
+				Request:    req,
+			}, nil
+		}
+	}
+
+	client := NewClient(Config{
+		BaseURL:     "http://127.0.0.1:0",
+		Middlewares: []Middleware{stub},
+	})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	data, _ := ParseRawResponse(resp)
+	if resp.StatusCode != http.StatusTeapot || string(data) != "stub" {
+		t.Errorf("响应 = %d %q，期望 418 \"stub\"", resp.StatusCode, data)
+	}
+}
+
+func TestClient_MiddlewareWrapsRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	var calls int
+	count := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			calls++
+			return next(req)
+		}
+	}
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		RetryDelay:  time.Millisecond,
+		Middlewares: []Middleware{count},
+	})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	// 中间件位于重试之外，一次逻辑请求只执行一次
+	if calls != 1 || attempts.Load() != 3 {
+		t.Errorf("中间件执行 %d 次、请求 %d 次，期望 1 次、3 次", calls, attempts.Load())
+	}
+}
+
+func TestClient_WithDoesNotModifyOriginal(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	var order []string
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		Middlewares: []Middleware{recordMiddleware("base", &order)},
+	})
+	_ = client.With(recordMiddleware("extra", &order))
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	want := []string{"base:before", "base:after"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("执行顺序 = %v，期望 %v", order, want)
+	}
+}
@@ -0,0 +1,230 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MultipartFile multipart 请求中的文件，Reader 和 Path 二选一
+type MultipartFile struct {
+	// FieldName 表单字段名
+	FieldName string
+	// FileName 文件名，为空时取 Path 的文件名，没有 Path 时使用 FieldName
+	FileName string
+	// ContentType 文件类型，默认 application/octet-stream
+	ContentType string
+	// Reader 文件内容；实现 io.Seeker 时请求可以重试，否则只发送一次
+	Reader io.Reader
+	// Path 本地文件路径，每次发送时重新打开，请求可以重试
+	Path string
+}
+
+// PostForm 以 application/x-www-form-urlencoded 发送 POST 请求
+func (c *Client) PostForm(ctx context.Context, path string, form url.Values, opts ...RequestOption) (*http.Response, error) {
+	o := newRequestOptions(opts)
+	o.headers["Content-Type"] = "application/x-www-form-urlencoded"
+	return c.do(ctx, http.MethodPost, path, strings.NewReader(form.Encode()), o)
+}
+
+// PostMultipart 以 multipart/form-data 发送 POST 请求
+// 请求体通过 io.Pipe 边读边发，不会把文件整体读入内存
+func (c *Client) PostMultipart(ctx context.Context, path string, fields url.Values, files []MultipartFile, opts ...RequestOption) (*http.Response, error) {
+	body, err := newMultipartBody(fields, files)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newRequestOptions(opts)
+	o.headers["Content-Type"] = "multipart/form-data; boundary=" + body.boundary
+	return c.do(ctx, http.MethodPost, path, body.reader(), o)
+}
+
+// multipartBody multipart 请求体的内容，可以多次生成
+type multipartBody struct {
+	boundary string
+	fields   url.Values
+	files    []MultipartFile
+	offsets  []int64 // 可 Seek 的 Reader 的起始位置
+	seekable bool    // 所有文件都可以重新读取
+}
+
+// newMultipartBody 校验文件参数并记录重放所需的信息
+func newMultipartBody(fields url.Values, files []MultipartFile) (*multipartBody, error) {
+	b := &multipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+		fields:   fields,
+		files:    files,
+		offsets:  make([]int64, len(files)),
+		seekable: true,
+	}
+	for i, file := range files {
+		if file.FieldName == "" {
+			return nil, errors.New("httpx: multipart 文件缺少 FieldName")
+		}
+		switch {
+		case file.Reader != nil && file.Path != "":
+			return nil, fmt.Errorf("httpx: multipart 文件 %q 不能同时设置 Reader 和 Path", file.FieldName)
+		case file.Path != "":
+			// 提前检查文件是否可读，避免请求发出后才失败
+			if _, err := os.Stat(file.Path); err != nil {
+				return nil, fmt.Errorf("httpx: multipart 文件 %q: %w", file.FieldName, err)
+			}
+		case file.Reader != nil:
+			seeker, ok := file.Reader.(io.Seeker)
+			if !ok {
+				b.seekable = false
+				continue
+			}
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			b.offsets[i] = offset
+		default:
+			return nil, fmt.Errorf("httpx: multipart 文件 %q 缺少 Reader 或 Path", file.FieldName)
+		}
+	}
+	return b, nil
+}
+
+// reader 返回请求体；所有文件都可以重新读取时，请求体可在重试时重新生成
+func (b *multipartBody) reader() io.Reader {
+	if !b.seekable {
+		return newLazyPipe(b.writeTo)
+	}
+	current := newLazyPipe(b.writeTo)
+	return &reopenableBody{
+		ReadCloser: current,
+		reopen: func() (io.ReadCloser, error) {
+			// 上一次尝试的写入协程可能仍在读取文件，退出后才能 Seek
+			current.closeAndWait()
+			for i, file := range b.files {
+				if seeker, ok := file.Reader.(io.Seeker); ok {
+					if _, err := seeker.Seek(b.offsets[i], io.SeekStart); err != nil {
+						return nil, err
+					}
+				}
+			}
+			current = newLazyPipe(b.writeTo)
+			return current, nil
+		},
+	}
+}
+
+// writeTo 按字段名顺序写入表单字段，再依次写入文件
+func (b *multipartBody) writeTo(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(b.fields))
+	for k := range b.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range b.fields[k] {
+			if err := mw.WriteField(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, file := range b.files {
+		if err := writeFilePart(mw, file); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// quoteEscaper 转义 Content-Disposition 中的引号和反斜杠
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// writeFilePart 写入单个文件
+func writeFilePart(mw *multipart.Writer, file MultipartFile) error {
+	fileName := file.FileName
+	if fileName == "" && file.Path != "" {
+		fileName = filepath.Base(file.Path)
+	}
+	// 没有文件名的部分会被服务端当作普通字段
+	if fileName == "" {
+		fileName = file.FieldName
+	}
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	reader := file.Reader
+	if file.Path != "" {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return fmt.Errorf("httpx: 打开 multipart 文件失败: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+	_, err = io.Copy(part, reader)
+	return err
+}
+
+// lazyPipe 首次读取时才启动写入协程的管道
+// 请求未真正发出（如熔断、限流）时不会留下阻塞的协程
+type lazyPipe struct {
+	once  sync.Once
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+	write func(w io.Writer) error
+	done  chan struct{} // 写入协程退出或确定不再启动时关闭
+}
+
+// newLazyPipe 创建管道，write 在独立协程中写入数据
+func newLazyPipe(write func(w io.Writer) error) *lazyPipe {
+	pr, pw := io.Pipe()
+	return &lazyPipe{pr: pr, pw: pw, write: write, done: make(chan struct{})}
+}
+
+// Read 实现 io.Reader 接口
+func (p *lazyPipe) Read(b []byte) (int, error) {
+	p.once.Do(func() {
+		go func() {
+			defer close(p.done)
+			p.pw.CloseWithError(p.write(p.pw))
+		}()
+	})
+	return p.pr.Read(b)
+}
+
+// Close 关闭管道，写入协程随之退出
+func (p *lazyPipe) Close() error {
+	return p.pr.Close()
+}
+
+// closeAndWait 关闭管道并等待写入协程退出，之后才能安全地重置 write 读取的数据源
+func (p *lazyPipe) closeAndWait() {
+	p.pr.Close()
+	p.once.Do(func() { close(p.done) })
+	<-p.done
+}
@@ -0,0 +1,217 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 表单测试 ====================
+
+func TestClient_PostForm(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		r.ParseForm()
+		if r.PostForm.Get("name") != "张三" || len(r.PostForm["tag"]) != 2 {
+			t.Errorf("表单 = %v", r.PostForm)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	resp, err := client.PostForm(context.Background(), "/form", url.Values{"name": {"张三"}, "tag": {"a", "b"}})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
+
+// ==================== multipart 测试 ====================
+
+func TestClient_PostMultipart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	os.WriteFile(path, []byte("a,b\n1,2\n"), 0o600)
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 流式发送，长度未知
+		if r.ContentLength != -1 {
+			t.Errorf("ContentLength = %d，期望分块传输", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("解析 multipart 失败: %v", err)
+		}
+		if got := r.MultipartForm.Value["title"]; len(got) != 1 || got[0] != "月报" {
+			t.Errorf("title = %v", got)
+		}
+
+		checks := []struct {
+			field, name, contentType, content string
+		}{
+			{"doc", "report.csv", "text/csv", "a,b\n1,2\n"},
+			{"raw", `we"ird.bin`, "application/octet-stream", "raw-bytes"},
+		}
+		for _, c := range checks {
+			headers := r.MultipartForm.File[c.field]
+			if len(headers) != 1 {
+				t.Fatalf("字段 %s 的文件数 = %d", c.field, len(headers))
+			}
+			f, _ := headers[0].Open()
+			data, _ := io.ReadAll(f)
+			f.Close()
+			if headers[0].Filename != c.name || headers[0].Header.Get("Content-Type") != c.contentType || string(data) != c.content {
+				t.Errorf("字段 %s: 文件名 %q，类型 %q，内容 %q", c.field, headers[0].Filename, headers[0].Header.Get("Content-Type"), data)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	resp, err := client.PostMultipart(context.Background(), "/upload",
+		url.Values{"title": {"月报"}},
+		[]MultipartFile{
+			{FieldName: "doc", Path: path, ContentType: "text/csv"},
+			{FieldName: "raw", FileName: `we"ird.bin`, Reader: io.NopCloser(strings.NewReader("raw-bytes"))},
+		},
+	)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClient_PostMultipartRetry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(path, bytes.Repeat([]byte("x"), 64<<10), 0o600)
+
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("第 %d 次请求解析失败: %v", n, err)
+		} else if headers := r.MultipartForm.File["file"]; len(headers) != 1 || headers[0].Size != 64<<10 {
+			t.Errorf("第 %d 次请求文件不完整: %v", n, r.MultipartForm.File)
+		}
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryDelay: 1})
+
+	tests := []struct {
+		name    string
+		file    MultipartFile
+		wantErr error
+	}{
+		{name: "文件路径可重放", file: MultipartFile{FieldName: "file", Path: path}},
+		{name: "可Seek的Reader可重放", file: MultipartFile{FieldName: "file", Reader: bytes.NewReader(bytes.Repeat([]byte("y"), 64<<10))}},
+		{name: "不可Seek的Reader不能重试", file: MultipartFile{FieldName: "file", Reader: io.MultiReader(bytes.NewReader(bytes.Repeat([]byte("z"), 64<<10)))}, wantErr: ErrBodyNotReplayable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts.Store(0)
+			resp, err := client.PostMultipart(context.Background(), "/upload", nil, []MultipartFile{tt.file})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("期望 %v，实际 %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+				t.Errorf("状态码 %d、请求 %d 次，期望 200、2 次", resp.StatusCode, attempts.Load())
+			}
+		})
+	}
+}
+
+// slowSeeker 每次读取前稍作等待的可 Seek 文件，模拟较慢的磁盘
+type slowSeeker struct {
+	r *bytes.Reader
+}
+
+func (s *slowSeeker) Read(p []byte) (int, error) {
+	time.Sleep(100 * time.Microsecond)
+	return s.r.Read(p)
+}
+
+func (s *slowSeeker) Seek(offset int64, whence int) (int64, error) {
+	return s.r.Seek(offset, whence)
+}
+
+func TestClient_PostMultipartRetryUnread(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100<<10)
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次不读取请求体直接返回，上一次的写入协程可能仍在读取文件
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("解析失败: %v", err)
+			return
+		}
+		defer file.Close()
+		if data, _ := io.ReadAll(file); !bytes.Equal(data, content) {
+			t.Errorf("重试的文件内容不一致，长度 %d", len(data))
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryDelay: 1})
+	file := MultipartFile{FieldName: "file", Reader: &slowSeeker{bytes.NewReader(content)}}
+	resp, err := client.PostMultipart(context.Background(), "/upload", nil, []MultipartFile{file})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("状态码 %d、请求 %d 次", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestClient_PostMultipartInvalid(t *testing.T) {
+	client := NewClient(Config{BaseURL: "http://127.0.0.1:0"})
+
+	tests := []struct {
+		name    string
+		file    MultipartFile
+		wantErr string
+	}{
+		{name: "缺少字段名", file: MultipartFile{Path: "a.txt"}, wantErr: "FieldName"},
+		{name: "缺少内容", file: MultipartFile{FieldName: "f"}, wantErr: "缺少 Reader 或 Path"},
+		{name: "同时设置", file: MultipartFile{FieldName: "f", Path: "a", Reader: strings.NewReader("")}, wantErr: "不能同时设置"},
+		{name: "文件不存在", file: MultipartFile{FieldName: "f", Path: "/nonexistent/file"}, wantErr: "no such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.PostMultipart(context.Background(), "/upload", nil, []MultipartFile{tt.file})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v，期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
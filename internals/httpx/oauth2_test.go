@@ -0,0 +1,198 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// createTokenServer 创建模拟的 OAuth2 令牌端点，每次签发新的令牌
+func createTokenServer(t *testing.T, expiresIn int, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var issued atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("解析表单失败: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("grant_type = %q", got)
+		}
+
+		// Basic 鉴权中的凭证经过表单编码
+		id, secret, ok := r.BasicAuth()
+		if ok {
+			id, _ = url.QueryUnescape(id)
+			secret, _ = url.QueryUnescape(secret)
+		} else {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if id != "svc" || secret != "p@ss" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+
+		time.Sleep(delay)
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d,"scope":%q}`, n, expiresIn, r.PostForm.Get("scope"))
+	})
+	return server, &issued
+}
+
+// ==================== 客户端凭证模式测试 ====================
+
+func TestClientCredentialsSource(t *testing.T) {
+	tokenServer, issued := createTokenServer(t, 3600, 0)
+	defer tokenServer.Close()
+
+	tests := []struct {
+		name       string
+		authInBody bool
+	}{
+		{name: "Basic鉴权", authInBody: false},
+		{name: "表单鉴权", authInBody: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued.Store(0)
+			source := NewClientCredentialsSource(ClientCredentialsConfig{
+				TokenURL:     tokenServer.URL + "/oauth/token",
+				ClientID:     "svc",
+				ClientSecret: "p@ss",
+				Scopes:       []string{"read", "write"},
+				AuthInBody:   tt.authInBody,
+			})
+
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				token, err := source.Token(ctx)
+				if err != nil {
+					t.Fatalf("获取令牌失败: %v", err)
+				}
+				if token != "token-1" {
+					t.Errorf("Token() = %q，期望缓存的 token-1", token)
+				}
+			}
+			if got := issued.Load(); got != 1 {
+				t.Errorf("令牌端点被请求 %d 次，期望 1 次", got)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsSource_Expiry(t *testing.T) {
+	// 有效期短于提前刷新的时间，每次都需要重新获取
+	tokenServer, issued := createTokenServer(t, 5, 0)
+	defer tokenServer.Close()
+
+	source := NewClientCredentialsSource(ClientCredentialsConfig{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "svc",
+		ClientSecret: "p@ss",
+	})
+
+	ctx := context.Background()
+	first, _ := source.Token(ctx)
+	second, _ := source.Token(ctx)
+	if first == second || issued.Load() != 2 {
+		t.Errorf("临近过期的令牌应被刷新: %q, %q, 签发 %d 次", first, second, issued.Load())
+	}
+}
+
+func TestClientCredentialsSource_Error(t *testing.T) {
+	tokenServer, _ := createTokenServer(t, 3600, 0)
+	defer tokenServer.Close()
+
+	source := NewClientCredentialsSource(ClientCredentialsConfig{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "svc",
+		ClientSecret: "wrong",
+	})
+
+	_, err := source.Token(context.Background())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("期望 401 HTTPError，实际 %v", err)
+	}
+	if string(httpErr.Body) != `{"error":"invalid_client"}` {
+		t.Errorf("Body = %q", httpErr.Body)
+	}
+}
+
+func TestClientCredentials_ConcurrentRequests(t *testing.T) {
+	tokenServer, issued := createTokenServer(t, 3600, 50*time.Millisecond)
+	defer tokenServer.Close()
+
+	apiServer := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer apiServer.Close()
+
+	client := NewClient(Config{
+		BaseURL: apiServer.URL,
+		Auth: ClientCredentials(ClientCredentialsConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "svc",
+			ClientSecret: "p@ss",
+		}),
+	})
+
+	const numRequests = 20
+	var wg sync.WaitGroup
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), "/test", nil)
+			if err != nil {
+				t.Errorf("请求失败: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	// 并发请求共享同一次令牌获取
+	if got := issued.Load(); got != 1 {
+		t.Errorf("令牌端点被请求 %d 次，期望 1 次", got)
+	}
+}
+
+func TestRefreshingTokenSource_WaitCancelled(t *testing.T) {
+	release := make(chan struct{})
+	source := NewRefreshingTokenSource(func(ctx context.Context) (*Token, error) {
+		<-release
+		return &Token{AccessToken: "slow"}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := source.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望 context.DeadlineExceeded，实际 %v", err)
+	}
+
+	// 取消的调用方不影响进行中的获取，其他调用方仍能拿到结果
+	close(release)
+	token, err := source.Token(context.Background())
+	if err != nil || token != "slow" {
+		t.Errorf("Token() = %q, %v，期望 slow", token, err)
+	}
+}
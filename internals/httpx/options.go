@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RequestOption 单次请求的可选参数
+type RequestOption func(*requestOptions)
+
+// requestOptions 单次请求参数
+type requestOptions struct {
+	headers    map[string]string
+	pathParams map[string]string
+	query      url.Values
+}
+
+// WithHeader 设置单个请求 header
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers[http.CanonicalHeaderKey(key)] = value
+	}
+}
+
+// WithHeaders 批量设置请求 header
+func WithHeaders(headers map[string]string) RequestOption {
+	return func(o *requestOptions) {
+		for k, v := range headers {
+			o.headers[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+}
+
+// WithPathParam 设置路径模板参数，替换 path 中的 {name}，值会被转义
+func WithPathParam(name string, value any) RequestOption {
+	return func(o *requestOptions) {
+		o.pathParams[name] = fmt.Sprint(value)
+	}
+}
+
+// WithQuery 追加单个查询参数
+func WithQuery(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.query.Add(key, value)
+	}
+}
+
+// WithQueryValues 追加一组查询参数
+func WithQueryValues(values url.Values) RequestOption {
+	return func(o *requestOptions) {
+		for k, vs := range values {
+			for _, v := range vs {
+				o.query.Add(k, v)
+			}
+		}
+	}
+}
+
+// newRequestOptions 应用请求参数
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{
+		headers:    make(map[string]string),
+		pathParams: make(map[string]string),
+		query:      make(url.Values),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// resolvePath 展开路径模板并追加查询参数
+func (o *requestOptions) resolvePath(path string) (string, error) {
+	path, err := expandPath(path, o.pathParams)
+	if err != nil {
+		return "", err
+	}
+	if len(o.query) == 0 {
+		return path, nil
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	ref.RawQuery = joinQuery(ref.RawQuery, o.query.Encode())
+	return ref.String(), nil
+}
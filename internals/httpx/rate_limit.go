@@ -0,0 +1,192 @@
+package httpx
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 超出客户端限流，且配置为快速失败
+var ErrRateLimited = errors.New("httpx: 超出客户端限流")
+
+// RateLimit 令牌桶参数
+type RateLimit struct {
+	// RPS 每秒生成的令牌数，0 表示不限流
+	RPS float64
+	// Burst 桶容量，即允许的突发请求数，默认为 RPS 向上取整且至少为 1
+	Burst int
+}
+
+// RateLimitConfig 客户端限流配置
+type RateLimitConfig struct {
+	// Limit 全局限流，所有请求共享
+	Limit RateLimit
+	// Routes 按 URL 路径前缀限流，匹配最长前缀，与全局限流同时生效
+	// 前缀需要包含 BaseURL 中的路径部分
+	Routes map[string]RateLimit
+	// FailFast 为 true 时超限立即返回 ErrRateLimited，否则等待令牌直到 ctx 结束
+	FailFast bool
+}
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 创建令牌桶，RPS 为 0 时返回 nil
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	if limit.RPS <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(limit.RPS))
+	}
+	return &tokenBucket{
+		rate:   limit.RPS,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// advance 按流逝的时间补充令牌，调用方需持有锁
+func (b *tokenBucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// reserve 预留一个令牌，返回拿到令牌前需要等待的时间
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// tryTake 立即取一个令牌，没有可用令牌时返回 false
+func (b *tokenBucket) tryTake(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refund 归还一个未使用的令牌
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// routeBucket 路径前缀对应的令牌桶
+type routeBucket struct {
+	prefix string
+	bucket *tokenBucket
+}
+
+// rateLimiter 全局和按路由的限流器
+type rateLimiter struct {
+	global   *tokenBucket
+	routes   []routeBucket // 按前缀长度降序排列
+	failFast bool
+	now      func() time.Time
+}
+
+// newRateLimiter 根据配置创建限流器
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	now := time.Now()
+	l := &rateLimiter{
+		global:   newTokenBucket(config.Limit, now),
+		failFast: config.FailFast,
+		now:      time.Now,
+	}
+	for prefix, limit := range config.Routes {
+		if bucket := newTokenBucket(limit, now); bucket != nil {
+			l.routes = append(l.routes, routeBucket{prefix: prefix, bucket: bucket})
+		}
+	}
+	sort.Slice(l.routes, func(i, j int) bool {
+		return len(l.routes[i].prefix) > len(l.routes[j].prefix)
+	})
+	return l
+}
+
+// buckets 返回请求需要经过的令牌桶
+func (l *rateLimiter) buckets(path string) []*tokenBucket {
+	var buckets []*tokenBucket
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+	for _, route := range l.routes {
+		if strings.HasPrefix(path, route.prefix) {
+			buckets = append(buckets, route.bucket)
+			break
+		}
+	}
+	return buckets
+}
+
+// middleware 限流中间件，位于重试之内，每次尝试都消耗一个令牌
+func (l *rateLimiter) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		buckets := l.buckets(req.URL.Path)
+		if l.failFast {
+			if !l.tryTake(buckets) {
+				return nil, ErrRateLimited
+			}
+			return next(req)
+		}
+
+		// 同时向所有桶预留令牌，等待其中最长的时间
+		now := l.now()
+		var wait time.Duration
+		for _, b := range buckets {
+			wait = max(wait, b.reserve(now))
+		}
+		if wait > 0 {
+			if err := sleepContext(req.Context(), wait); err != nil {
+				for _, b := range buckets {
+					b.refund()
+				}
+				return nil, err
+			}
+		}
+		return next(req)
+	}
+}
+
+// tryTake 从所有桶各取一个令牌，任一失败则归还已取的令牌
+func (l *rateLimiter) tryTake(buckets []*tokenBucket) bool {
+	now := l.now()
+	for i, b := range buckets {
+		if !b.tryTake(now) {
+			for _, taken := range buckets[:i] {
+				taken.refund()
+			}
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 令牌桶测试 ====================
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newTokenBucket(RateLimit{RPS: 10, Burst: 2}, start)
+
+	// 初始桶是满的
+	if !b.tryTake(start) || !b.tryTake(start) {
+		t.Fatal("突发容量内应立即拿到令牌")
+	}
+	if b.tryTake(start) {
+		t.Fatal("桶已空，不应拿到令牌")
+	}
+
+	// 100ms 补充一个令牌
+	if !b.tryTake(start.Add(100 * time.Millisecond)) {
+		t.Fatal("100ms 后应补充一个令牌")
+	}
+
+	// 预留返回需要等待的时间
+	if wait := b.reserve(start.Add(100 * time.Millisecond)); wait != 100*time.Millisecond {
+		t.Errorf("reserve() = %v，期望 100ms", wait)
+	}
+	b.refund()
+	if wait := b.reserve(start.Add(200 * time.Millisecond)); wait != 0 {
+		t.Errorf("归还后 reserve() = %v，期望 0", wait)
+	}
+
+	// 补充的令牌不超过桶容量
+	b.advance(start.Add(time.Hour))
+	if b.tokens != 2 {
+		t.Errorf("tokens = %v，期望 2", b.tokens)
+	}
+}
+
+func TestNewTokenBucket(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     RateLimit
+		wantNil   bool
+		wantBurst float64
+	}{
+		{name: "不限流", limit: RateLimit{}, wantNil: true},
+		{name: "默认桶容量", limit: RateLimit{RPS: 2.5}, wantBurst: 3},
+		{name: "低速率至少为1", limit: RateLimit{RPS: 0.1}, wantBurst: 1},
+		{name: "自定义桶容量", limit: RateLimit{RPS: 5, Burst: 20}, wantBurst: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(tt.limit, time.Now())
+			if (b == nil) != tt.wantNil {
+				t.Fatalf("newTokenBucket() = %v，wantNil %v", b, tt.wantNil)
+			}
+			if b != nil && b.burst != tt.wantBurst {
+				t.Errorf("burst = %v，期望 %v", b.burst, tt.wantBurst)
+			}
+		})
+	}
+}
+
+// ==================== 客户端限流测试 ====================
+
+func TestClient_RateLimitFailFast(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimitConfig{
+			Limit:    RateLimit{RPS: 0.01, Burst: 2},
+			FailFast: true,
+		},
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ctx, "/test", nil)
+		if err != nil {
+			t.Fatalf("第 %d 次请求失败: %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	if _, err := client.Get(ctx, "/test", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("期望 ErrRateLimited，实际 %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("期望请求 2 次，实际 %d 次", got)
+	}
+}
+
+func TestClient_RateLimitRoutes(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimitConfig{
+			Routes: map[string]RateLimit{
+				"/search":        {RPS: 0.01, Burst: 1},
+				"/search/export": {RPS: 0.01, Burst: 2},
+			},
+			FailFast: true,
+		},
+	})
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/search?q=1", false},
+		{"/search?q=2", true},     // /search 已用完
+		{"/search/export", false}, // 最长前缀有独立的令牌桶
+		{"/search/export", false},
+		{"/search/export", true},
+		{"/users", false}, // 未匹配的路由不限流
+		{"/users", false},
+	}
+
+	ctx := context.Background()
+	for i, tt := range tests {
+		resp, err := client.Get(ctx, tt.path, nil)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("第 %d 次请求 %s: error = %v, wantErr %v", i+1, tt.path, err, tt.wantErr)
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+func TestClient_RateLimitWait(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimitConfig{
+			Limit: RateLimit{RPS: 20, Burst: 1},
+		},
+	})
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ctx, "/test", nil)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// 第 2、3 次请求各需等待约 50ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("耗时 %v，期望至少 100ms", elapsed)
+	}
+}
+
+func TestClient_RateLimitWaitCancelled(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimitConfig{
+			Limit: RateLimit{RPS: 0.01, Burst: 1},
+		},
+	})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.Get(ctx, "/test", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望 context.DeadlineExceeded，实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ctx 结束后等待了 %v，应立即返回", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("期望请求 1 次，实际 %d 次", got)
+	}
+}
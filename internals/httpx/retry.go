@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy 重试策略，根据单次尝试的结果决定是否需要重试
+// attempt 为本次尝试的序号（从 0 开始），resp 和 err 有且仅有一个非 nil
+type RetryPolicy func(attempt int, resp *http.Response, err error) bool
+
+// DefaultRetryPolicy 默认重试策略
+// 传输层错误（连接重置、超时等）、5xx 和 429 重试，4xx、主动取消、熔断及限流不重试
+func DefaultRetryPolicy(attempt int, resp *http.Response, err error) bool {
+	if err != nil {
+		return isRetryableError(err)
+	}
+	return isRetryableStatus(resp.StatusCode)
+}
+
+// isRetryableError 判断传输层错误是否可以重试
+func isRetryableError(err error) bool {
+	// 调用方主动取消的请求没有重试的意义，熔断期间重试只会加重下游负担
+	// 限流配置为快速失败时也不应通过重试绕过
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, ErrCircuitOpen) &&
+		!errors.Is(err, ErrRateLimited)
+}
+
+// isRetryableStatus 判断响应状态码是否可以重试
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// attemptKey 上下文中保存尝试序号的 key
+type attemptKey struct{}
+
+// AttemptFromContext 返回当前尝试的序号（从 0 开始）
+// 只在重试之内的环节（如鉴权、日志）中有意义
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// drainBody 读完并关闭响应体，使底层连接可以被复用
+func drainBody(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	resp.Body.Close()
+}
+
+// retry 内置重试中间件：由重试策略根据状态码和错误决定是否继续
+func (c *Client) retry(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+
+		var resp *http.Response
+		var err error
+		var delay time.Duration
+		for attempt := 0; ; attempt++ {
+			if c.hedger != nil && canHedge(req) {
+				// 对冲发出的尝试计入重试次数
+				var sent int
+				resp, sent, err = c.hedge(ctx, req, attempt, next)
+				attempt += sent - 1
+			} else {
+				resp, err = next(req.WithContext(context.WithValue(ctx, attemptKey{}, attempt)))
+			}
+			if attempt >= c.retryTimes || ctx.Err() != nil || !c.retryPolicy(attempt, resp, err) {
+				break
+			}
+			var ok bool
+			if delay, ok = c.nextDelay(attempt, delay, resp); !ok {
+				break
+			}
+			// 丢弃本次响应，释放连接
+			if resp != nil {
+				drainBody(resp)
+			}
+			// 流式请求体已被消费，重试会发送残缺数据
+			if !isReplayable(req) {
+				if resp != nil {
+					return nil, fmt.Errorf("%w（上次响应状态码 %d）", ErrBodyNotReplayable, resp.StatusCode)
+				}
+				return nil, fmt.Errorf("%w: %w", ErrBodyNotReplayable, err)
+			}
+			// 等待期间 ctx 取消则立即返回
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, err
+			}
+
+			// 每次重试都重新发送完整的请求体
+			if req, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return resp, nil
+	}
+}
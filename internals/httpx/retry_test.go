@@ -0,0 +1,194 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// ==================== DefaultRetryPolicy 测试 ====================
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{name: "200 不重试", status: http.StatusOK, want: false},
+		{name: "400 不重试", status: http.StatusBadRequest, want: false},
+		{name: "404 不重试", status: http.StatusNotFound, want: false},
+		{name: "429 重试", status: http.StatusTooManyRequests, want: true},
+		{name: "500 重试", status: http.StatusInternalServerError, want: true},
+		{name: "502 重试", status: http.StatusBadGateway, want: true},
+		{name: "503 重试", status: http.StatusServiceUnavailable, want: true},
+		{name: "连接重置重试", err: syscall.ECONNRESET, want: true},
+		{name: "意外EOF重试", err: io.ErrUnexpectedEOF, want: true},
+		{name: "主动取消不重试", err: context.Canceled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			if got := DefaultRetryPolicy(0, resp, tt.err); got != tt.want {
+				t.Errorf("DefaultRetryPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== 按状态码重试测试 ====================
+
+func TestClient_RetryOnStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // 服务端依次返回的状态码，超出部分返回最后一个
+		maxRetries   int
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{
+			name:         "503后成功",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "429后成功",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "4xx不重试",
+			statuses:     []int{http.StatusNotFound},
+			maxRetries:   3,
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+		{
+			name:         "重试耗尽返回最后一次响应",
+			statuses:     []int{http.StatusBadGateway},
+			maxRetries:   2,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 3,
+		},
+		{
+			name:         "负数不重试",
+			statuses:     []int{http.StatusBadGateway},
+			maxRetries:   -1,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1)) - 1
+				if n >= len(tt.statuses) {
+					n = len(tt.statuses) - 1
+				}
+				w.WriteHeader(tt.statuses[n])
+			})
+			defer server.Close()
+
+			client := NewClient(Config{
+				BaseURL:    server.URL,
+				Timeout:    5 * time.Second,
+				MaxRetries: tt.maxRetries,
+				RetryDelay: time.Millisecond,
+			})
+
+			resp, err := client.Get(context.Background(), "/test", nil)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("期望状态码 %d，实际 %d", tt.wantStatus, resp.StatusCode)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("期望请求 %d 次，实际 %d 次", tt.wantAttempts, got)
+			}
+		})
+	}
+}
+
+// ==================== 自定义重试策略测试 ====================
+
+func TestClient_CustomRetryPolicy(t *testing.T) {
+	var attempts atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusConflict)
+	})
+	defer server.Close()
+
+	var gotAttempts []int
+	client := NewClient(Config{
+		BaseURL:    server.URL,
+		MaxRetries: 5,
+		RetryDelay: time.Millisecond,
+		// 409 重试，最多尝试 2 次
+		RetryPolicy: func(attempt int, resp *http.Response, err error) bool {
+			gotAttempts = append(gotAttempts, attempt)
+			return resp != nil && resp.StatusCode == http.StatusConflict && attempt < 1
+		},
+	})
+
+	resp, err := client.Get(context.Background(), "/test", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("期望请求 2 次，实际 %d 次", got)
+	}
+	if len(gotAttempts) != 2 || gotAttempts[0] != 0 || gotAttempts[1] != 1 {
+		t.Errorf("策略收到的 attempt = %v，期望 [0 1]", gotAttempts)
+	}
+}
+
+func TestClient_RetryOnTransportError(t *testing.T) {
+	// 关闭的服务器地址会产生连接错误
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {})
+	url := server.URL
+	server.Close()
+
+	var calls atomic.Int32
+	client := NewClient(Config{
+		BaseURL:    url,
+		MaxRetries: 2,
+		RetryDelay: time.Millisecond,
+		RetryPolicy: func(attempt int, resp *http.Response, err error) bool {
+			calls.Add(1)
+			return DefaultRetryPolicy(attempt, resp, err)
+		},
+	})
+
+	_, err := client.Get(context.Background(), "/test", nil)
+	if err == nil {
+		t.Fatal("期望连接错误，实际成功")
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("不期望的取消错误: %v", err)
+	}
+	// 最后一次尝试不再询问策略
+	if got := calls.Load(); got != 2 {
+		t.Errorf("期望策略被调用 2 次，实际 %d 次", got)
+	}
+}
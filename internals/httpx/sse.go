@@ -0,0 +1,189 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSSERetry 服务端未指定 retry 时的重连间隔
+const defaultSSERetry = 3 * time.Second
+
+// maxSSELineSize 单行事件数据的上限
+const maxSSELineSize = 1 << 20
+
+// Event Server-Sent Events 事件
+type Event struct {
+	// ID 最近一次收到的事件 ID，重连时通过 Last-Event-ID 发送
+	ID string
+	// Event 事件类型，默认 message
+	Event string
+	// Data 事件数据，多行 data 以换行连接
+	Data string
+	// Retry 服务端要求的重连间隔，未指定时为 0
+	Retry time.Duration
+}
+
+// SSE 订阅 text/event-stream，返回事件迭代器
+// 连接断开后按服务端的 retry（默认 3 秒）携带 Last-Event-ID 自动重连，直到 ctx 结束或停止迭代
+// 连接失败时产出错误，继续迭代会在等待后重连；4xx 响应和 204 表示服务端要求停止，不再重连
+// 连接建立后的读取中断视为断线，直接重连而不产出错误
+// 长连接会受 Config.Timeout 限制，到期后同样会重连，可把 Timeout 设为负数避免频繁重连
+func (c *Client) SSE(ctx context.Context, path string, opts ...RequestOption) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		s := &sseStream{retry: defaultSSERetry}
+		for {
+			err := c.streamEvents(ctx, path, opts, s, yield)
+			if s.stopped || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if !yield(Event{}, err) || isFinalSSEError(err) {
+					return
+				}
+			}
+			if sleepContext(ctx, s.retry) != nil {
+				return
+			}
+		}
+	}
+}
+
+// isFinalSSEError 判断错误是否表示不应再重连
+func isFinalSSEError(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode < http.StatusInternalServerError && httpErr.StatusCode != http.StatusTooManyRequests
+	}
+	return errors.Is(err, errNotEventStream)
+}
+
+// errNotEventStream 响应不是 text/event-stream
+var errNotEventStream = errors.New("httpx: 响应不是 text/event-stream")
+
+// sseStream 跨重连保持的解析状态
+type sseStream struct {
+	lastEventID string
+	retry       time.Duration
+	stopped     bool // 调用方停止迭代或服务端要求停止
+}
+
+// streamEvents 建立一次连接并产出事件，连接结束时返回
+func (c *Client) streamEvents(ctx context.Context, path string, opts []RequestOption, s *sseStream, yield func(Event, error) bool) error {
+	o := newRequestOptions(opts)
+	o.headers["Accept"] = "text/event-stream"
+	o.headers["Cache-Control"] = "no-cache"
+	if s.lastEventID != "" {
+		o.headers["Last-Event-Id"] = s.lastEventID
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil, o)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		s.stopped = true
+		return nil
+	}
+	if err := CheckResponse(resp); err != nil {
+		return err
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return fmt.Errorf("%w: %s", errNotEventStream, resp.Header.Get("Content-Type"))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxSSELineSize)
+	scanner.Split(scanSSELines)
+
+	var event Event
+	var data strings.Builder
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		// 空行分发事件
+		if line == "" {
+			if data.Len() > 0 {
+				event.ID = s.lastEventID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				if !yield(event, nil) {
+					s.stopped = true
+					return nil
+				}
+			}
+			event = Event{}
+			data.Reset()
+			continue
+		}
+		// 冒号开头为注释，常用于保活
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+				event.Retry = s.retry
+			}
+		}
+	}
+	// 读取中断（包括 Config.Timeout 到期）按断线处理，未完成的事件被丢弃
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("httpx: SSE 单行超过 %d 字节: %w", maxSSELineSize, err)
+	}
+	return nil
+}
+
+// scanSSELines 按 CRLF、LF 或单独的 CR 切分行
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// CR 后可能紧跟 LF，需要更多数据才能判断
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,282 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// collectEvents 读取最多 n 个事件，遇到错误时停止
+func collectEvents(t *testing.T, client *Client, path string, n int, opts ...RequestOption) ([]Event, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []Event
+	for event, err := range client.SSE(ctx, path, opts...) {
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+		if len(events) == n {
+			break
+		}
+	}
+	return events, nil
+}
+
+// writeEventStream 以 text/event-stream 写入原始内容
+func writeEventStream(w http.ResponseWriter, raw string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Write([]byte(raw))
+	w.(http.Flusher).Flush()
+}
+
+// ==================== SSE 解析测试 ====================
+
+func TestClient_SSEParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []Event
+	}{
+		{
+			name: "默认事件类型",
+			raw:  "data: hello\n\n",
+			want: []Event{{Event: "message", Data: "hello"}},
+		},
+		{
+			name: "多行数据和事件类型",
+			raw:  "event: update\ndata: line1\ndata: line2\nid: 7\n\n",
+			want: []Event{{ID: "7", Event: "update", Data: "line1\nline2"}},
+		},
+		{
+			name: "注释和未知字段被忽略",
+			raw:  ": keep-alive\nfoo: bar\ndata:no-space\n\n",
+			want: []Event{{Event: "message", Data: "no-space"}},
+		},
+		{
+			name: "CRLF和CR换行",
+			raw:  "data: a\r\n\r\ndata: b\r\rdata: c\n\n",
+			want: []Event{{Event: "message", Data: "a"}, {Event: "message", Data: "b"}, {Event: "message", Data: "c"}},
+		},
+		{
+			name: "ID在后续事件中保留",
+			raw:  "id: 1\ndata: a\n\ndata: b\n\nid\ndata: c\n\n",
+			want: []Event{{ID: "1", Event: "message", Data: "a"}, {ID: "1", Event: "message", Data: "b"}, {ID: "", Event: "message", Data: "c"}},
+		},
+		{
+			name: "retry字段",
+			raw:  "retry: 1500\ndata: a\n\nretry: abc\ndata: b\n\n",
+			want: []Event{{Event: "message", Data: "a", Retry: 1500 * time.Millisecond}, {Event: "message", Data: "b"}},
+		},
+		{
+			name: "没有数据的事件不分发",
+			raw:  "event: ping\n\ndata\n\n",
+			want: []Event{{Event: "message", Data: ""}},
+		},
+		{
+			name: "去掉开头的BOM",
+			raw:  "\ufeffdata: bom\n\n",
+			want: []Event{{Event: "message", Data: "bom"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				writeEventStream(w, tt.raw)
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL})
+			events, err := collectEvents(t, client, "/events", len(tt.want))
+			if err != nil {
+				t.Fatalf("SSE 失败: %v", err)
+			}
+			if fmt.Sprint(events) != fmt.Sprint(tt.want) {
+				t.Errorf("事件 = %+v，期望 %+v", events, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== SSE 重连测试 ====================
+
+func TestClient_SSEReconnect(t *testing.T) {
+	var connections atomic.Int32
+	var mu sync.Mutex
+	var lastEventIDs []string
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := connections.Add(1)
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+		if r.Header.Get("Accept") != "text/event-stream" || r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Client") != "test" {
+			t.Errorf("请求 header = %v", r.Header)
+		}
+		// 每次连接发送两个事件后断开，最后一个事件不完整
+		writeEventStream(w, fmt.Sprintf("retry: 10\nid: %d\ndata: a%d\n\nid: %d-b\ndata: b%d\n\ndata: partial", n, n, n, n))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Headers: map[string]string{"X-Client": "test"},
+		Auth:    BearerToken("secret"),
+	})
+	events, err := collectEvents(t, client, "/events", 5)
+	if err != nil {
+		t.Fatalf("SSE 失败: %v", err)
+	}
+
+	var data []string
+	for _, e := range events {
+		data = append(data, e.Data)
+	}
+	if got := strings.Join(data, ","); got != "a1,b1,a2,b2,a3" {
+		t.Errorf("事件数据 = %s", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(lastEventIDs, ","); got != ",1-b,2-b" {
+		t.Errorf("Last-Event-ID = %s", got)
+	}
+}
+
+func TestClient_SSEStop(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     func(w http.ResponseWriter, r *http.Request)
+		wantErr     bool
+		wantStatus  int
+		wantConnect int32
+	}{
+		{
+			name:        "204表示不再重连",
+			handler:     func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantConnect: 1,
+		},
+		{
+			name:        "4xx返回错误并停止",
+			handler:     func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) },
+			wantErr:     true,
+			wantStatus:  http.StatusNotFound,
+			wantConnect: 1,
+		},
+		{
+			name: "非事件流返回错误并停止",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{}`))
+			},
+			wantErr:     true,
+			wantConnect: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var connections atomic.Int32
+			server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+				connections.Add(1)
+				tt.handler(w, r)
+			})
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1})
+			var errs []error
+			for _, err := range client.SSE(context.Background(), "/events") {
+				errs = append(errs, err)
+			}
+
+			if (len(errs) > 0) != tt.wantErr {
+				t.Fatalf("错误 = %v，期望出错 %v", errs, tt.wantErr)
+			}
+			var httpErr *HTTPError
+			if tt.wantStatus != 0 && (!errors.As(errs[0], &httpErr) || httpErr.StatusCode != tt.wantStatus) {
+				t.Errorf("期望状态码 %d，实际 %v", tt.wantStatus, errs[0])
+			}
+			if connections.Load() != tt.wantConnect {
+				t.Errorf("连接 %d 次，期望 %d 次", connections.Load(), tt.wantConnect)
+			}
+		})
+	}
+}
+
+func TestClient_SSEServerErrorRetry(t *testing.T) {
+	var connections atomic.Int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch connections.Add(1) {
+		case 1:
+			// 先缩短重连间隔
+			writeEventStream(w, "retry: 10\n\n")
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			writeEventStream(w, "data: ok\n\n")
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 服务端错误产出后继续迭代会重连
+	var errs int
+	for event, err := range client.SSE(ctx, "/events") {
+		if err != nil {
+			errs++
+			continue
+		}
+		if event.Data != "ok" {
+			t.Errorf("事件数据 = %q", event.Data)
+		}
+		break
+	}
+	if errs != 1 || connections.Load() != 3 {
+		t.Errorf("错误 %d 次、连接 %d 次，期望 1 次、3 次", errs, connections.Load())
+	}
+}
+
+func TestClient_SSEContextCancel(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		writeEventStream(w, "data: first\n\n")
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan int)
+	go func() {
+		var n int
+		for _, err := range client.SSE(ctx, "/events") {
+			if err != nil {
+				t.Errorf("取消后不应产出错误: %v", err)
+			}
+			n++
+			cancel()
+		}
+		done <- n
+	}()
+
+	select {
+	case n := <-done:
+		if n != 1 {
+			t.Errorf("收到 %d 个事件，期望 1 个", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消 ctx 后迭代未结束")
+	}
+}
@@ -0,0 +1,114 @@
+package httpx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+)
+
+// StreamResponse 逐个解码响应中的 JSON 元素，适合无法一次读入内存的大响应
+// 支持 NDJSON（每行一个 JSON 值）和顶层 JSON 数组；Content-Type 为 application/x-ndjson、
+// application/jsonl 等时按 NDJSON 处理，否则根据第一个非空白字符是否为 '[' 判断
+// 非 2xx 响应产出 *HTTPError；解码失败时产出错误并结束；迭代结束或中途停止都会关闭响应体
+// 响应体只能读取一次，返回的迭代器不能重复使用
+func StreamResponse[T any](resp *http.Response) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if err := CheckResponse(resp); err != nil {
+			yield(zero, err)
+			return
+		}
+		defer drainBody(resp)
+
+		reader := bufio.NewReader(resp.Body)
+		array, err := isJSONArray(resp.Header.Get("Content-Type"), reader)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		dec := json.NewDecoder(reader)
+		if array {
+			streamArray(dec, yield)
+		} else {
+			streamValues(dec, yield)
+		}
+	}
+}
+
+// isJSONArray 判断响应是否为顶层 JSON 数组，只预读不消费
+func isJSONArray(contentType string, reader *bufio.Reader) (bool, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	ndjson := ndjsonTypes[mediaType]
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return false, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		default:
+			return !ndjson && b[0] == '[', nil
+		}
+	}
+}
+
+// ndjsonTypes 按行分隔 JSON 的媒体类型
+var ndjsonTypes = map[string]bool{
+	"application/x-ndjson": true,
+	"application/ndjson":   true,
+	"application/jsonl":    true,
+	"application/x-jsonl":  true,
+}
+
+// streamArray 逐个解码顶层数组的元素
+func streamArray[T any](dec *json.Decoder, yield func(T, error) bool) {
+	var zero T
+	// 消费开头的 '['
+	if _, err := dec.Token(); err != nil {
+		yield(zero, err)
+		return
+	}
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			yield(zero, err)
+			return
+		}
+		if !yield(item, nil) {
+			return
+		}
+	}
+	if token, err := dec.Token(); err != nil {
+		yield(zero, err)
+	} else if token != json.Delim(']') {
+		yield(zero, fmt.Errorf("httpx: JSON 数组结尾异常: %v", token))
+	}
+}
+
+// streamValues 逐个解码以空白分隔的 JSON 值
+func streamValues[T any](dec *json.Decoder, yield func(T, error) bool) {
+	var zero T
+	for {
+		var item T
+		err := dec.Decode(&item)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if !yield(item, nil) {
+			return
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// streamResponse 构造带指定 Content-Type 和响应体的响应
+func streamResponse(status int, contentType string, body io.ReadCloser) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          body,
+		ContentLength: -1,
+	}
+}
+
+// ==================== StreamResponse 测试 ====================
+
+func TestStreamResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []user
+		wantErr     error
+		wantErrText string
+	}{
+		{
+			name:        "NDJSON",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\":1,\"name\":\"alice\"}\n{\"id\":2,\"name\":\"bob\"}\n",
+			want:        []user{{1, "alice"}, {2, "bob"}},
+		},
+		{
+			name:        "未声明类型的按行JSON",
+			contentType: "text/plain",
+			body:        "{\"id\":1}\r\n\r\n{\"id\":2}",
+			want:        []user{{ID: 1}, {ID: 2}},
+		},
+		{
+			name:        "顶层数组",
+			contentType: "application/json; charset=utf-8",
+			body:        "\n  [ {\"id\":1,\"name\":\"alice\"},\n {\"id\":2,\"name\":\"bob\"} ]\n",
+			want:        []user{{1, "alice"}, {2, "bob"}},
+		},
+		{
+			name:        "空数组",
+			contentType: "application/json",
+			body:        "[]",
+		},
+		{
+			name:        "空响应体",
+			contentType: "application/json",
+			body:        "  \n",
+		},
+		{
+			name:        "NDJSON中途解码失败",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\":1}\n{\"id\":\"x\"}\n{\"id\":3}\n",
+			want:        []user{{ID: 1}},
+			wantErrText: "cannot unmarshal",
+		},
+		{
+			name:        "数组被截断",
+			contentType: "application/json",
+			body:        `[{"id":1},{"id":2}`,
+			want:        []user{{ID: 1}, {ID: 2}},
+			wantErrText: "unexpected end of JSON input",
+		},
+		{
+			name:        "数组元素被截断",
+			contentType: "application/json",
+			body:        `[{"id":1},{"id":`,
+			want:        []user{{ID: 1}},
+			wantErr:     io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeTracker{Reader: strings.NewReader(tt.body)}
+			var got []user
+			var gotErr error
+			for item, err := range StreamResponse[user](streamResponse(http.StatusOK, tt.contentType, body)) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				got = append(got, item)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("元素 = %v，期望 %v", got, tt.want)
+			}
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(gotErr, tt.wantErr) {
+					t.Errorf("error = %v，期望 %v", gotErr, tt.wantErr)
+				}
+			case tt.wantErrText != "":
+				if gotErr == nil || !strings.Contains(gotErr.Error(), tt.wantErrText) {
+					t.Errorf("error = %v，期望包含 %q", gotErr, tt.wantErrText)
+				}
+			case gotErr != nil:
+				t.Errorf("意外错误: %v", gotErr)
+			}
+			if !body.closed {
+				t.Error("响应体未关闭")
+			}
+		})
+	}
+}
+
+func TestStreamResponse_NDJSONArrays(t *testing.T) {
+	// 声明为 NDJSON 时，以 '[' 开头的行是元素本身而不是顶层数组
+	body := io.NopCloser(strings.NewReader("[1,2]\n[3]\n"))
+	var got [][]int
+	for item, err := range StreamResponse[[]int](streamResponse(http.StatusOK, "application/x-ndjson", body)) {
+		if err != nil {
+			t.Fatalf("解码失败: %v", err)
+		}
+		got = append(got, item)
+	}
+	if fmt.Sprint(got) != "[[1 2] [3]]" {
+		t.Errorf("元素 = %v", got)
+	}
+}
+
+func TestStreamResponse_HTTPError(t *testing.T) {
+	body := io.NopCloser(strings.NewReader(`{"error":"forbidden"}`))
+	var calls int
+	for _, err := range StreamResponse[user](streamResponse(http.StatusForbidden, "application/json", body)) {
+		calls++
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+			t.Errorf("期望 403 HTTPError，实际 %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("产出 %d 次，期望 1 次", calls)
+	}
+}
+
+func TestStreamResponse_Break(t *testing.T) {
+	// 提前停止时不会读完剩余内容
+	body := &closeTracker{Reader: io.MultiReader(strings.NewReader(`[{"id":1},`), &infiniteUsers{})}
+	var n int
+	for _, err := range StreamResponse[user](streamResponse(http.StatusOK, "application/json", body)) {
+		if err != nil {
+			t.Fatalf("解码失败: %v", err)
+		}
+		if n++; n == 100 {
+			break
+		}
+	}
+	if !body.closed {
+		t.Error("提前停止后响应体未关闭")
+	}
+}
+
+// infiniteUsers 无限重复的数组元素
+type infiniteUsers struct {
+	offset int
+}
+
+func (r *infiniteUsers) Read(p []byte) (int, error) {
+	const item = `{"id":2,"name":"bob"},`
+	for i := range p {
+		p[i] = item[r.offset]
+		r.offset = (r.offset + 1) % len(item)
+	}
+	return len(p), nil
+}
+
+func TestStreamResponse_Server(t *testing.T) {
+	const total = 10000
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 1; i <= total; i++ {
+			fmt.Fprintf(w, "{\"id\":%d,\"name\":\"user-%d\"}\n", i, i)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	resp, err := client.Get(context.Background(), "/export", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+
+	var n int
+	for item, err := range StreamResponse[user](resp) {
+		if err != nil {
+			t.Fatalf("解码失败: %v", err)
+		}
+		if n++; item.ID != n {
+			t.Fatalf("第 %d 个元素 ID = %d", n, item.ID)
+		}
+	}
+	if n != total {
+		t.Errorf("收到 %d 个元素，期望 %d 个", n, total)
+	}
+}
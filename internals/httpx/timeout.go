@@ -0,0 +1,201 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// 各阶段超时对应的错误，可通过 errors.Is 区分
+var (
+	ErrDialTimeout           = errors.New("httpx: 建立连接超时")
+	ErrTLSHandshakeTimeout   = errors.New("httpx: TLS 握手超时")
+	ErrResponseHeaderTimeout = errors.New("httpx: 等待响应头超时")
+	ErrBodyReadTimeout       = errors.New("httpx: 读取响应体超时")
+	ErrTotalTimeout          = errors.New("httpx: 请求总超时")
+)
+
+// TimeoutError 某个阶段超时，Unwrap 返回对应的 Err*Timeout
+type TimeoutError struct {
+	err     error
+	timeout time.Duration
+}
+
+// Error 实现 error 接口
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%v（%v）", e.err, e.timeout)
+}
+
+// Unwrap 返回阶段对应的错误
+func (e *TimeoutError) Unwrap() error {
+	return e.err
+}
+
+// Timeout 与 net.Error 一致，表示这是超时错误
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// timeoutCause 返回 ctx 因超时被取消时的 *TimeoutError
+func timeoutCause(ctx context.Context) (*TimeoutError, bool) {
+	var timeoutErr *TimeoutError
+	if ctx.Err() == nil || !errors.As(context.Cause(ctx), &timeoutErr) {
+		return nil, false
+	}
+	return timeoutErr, true
+}
+
+// timeouts 分阶段超时配置
+type timeouts struct {
+	dial         time.Duration
+	tlsHandshake time.Duration
+	header       time.Duration
+	bodyRead     time.Duration
+}
+
+// enabled 判断是否设置了任一阶段的超时
+func (t timeouts) enabled() bool {
+	return t.dial > 0 || t.tlsHandshake > 0 || t.header > 0 || t.bodyRead > 0
+}
+
+// ==================== 总超时 ====================
+
+// totalTimeout 总超时中间件，位于重试之外，截止时间覆盖所有尝试、重试等待和读取响应体
+func totalTimeout(timeout time.Duration) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeoutCause(req.Context(), timeout, &TimeoutError{err: ErrTotalTimeout, timeout: timeout})
+			resp, err := next(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+				if timeoutErr, ok := timeoutCause(ctx); ok {
+					return nil, timeoutErr
+				}
+				return nil, err
+			}
+			resp.Body = &timeoutBody{ReadCloser: resp.Body, ctx: ctx, done: cancel}
+			return resp, nil
+		}
+	}
+}
+
+// ==================== 分阶段超时 ====================
+
+// phaseTimer 单个阶段的计时器，超时后以 cause 取消本次尝试
+type phaseTimer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	timeout time.Duration
+	cause   error
+	cancel  context.CancelCauseFunc
+}
+
+// start 开始计时，重复调用会重新计时
+func (p *phaseTimer) start() {
+	if p.timeout <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(p.timeout, func() { p.cancel(p.cause) })
+}
+
+// stop 停止计时
+func (p *phaseTimer) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+// phaseTimeouts 分阶段超时中间件，位于最内层，每次尝试单独计时
+// 通过 httptrace 感知连接、握手、等待响应头各阶段，对自定义 RoundTripper 同样有效
+func phaseTimeouts(t timeouts) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithCancelCause(req.Context())
+			newTimer := func(timeout time.Duration, err error) *phaseTimer {
+				return &phaseTimer{timeout: timeout, cause: &TimeoutError{err: err, timeout: timeout}, cancel: cancel}
+			}
+			dial := newTimer(t.dial, ErrDialTimeout)
+			handshake := newTimer(t.tlsHandshake, ErrTLSHandshakeTimeout)
+			header := newTimer(t.header, ErrResponseHeaderTimeout)
+			body := newTimer(t.bodyRead, ErrBodyReadTimeout)
+			stopAll := func() {
+				dial.stop()
+				handshake.stop()
+				header.stop()
+			}
+
+			ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+				ConnectStart:         func(network, addr string) { dial.start() },
+				ConnectDone:          func(network, addr string, err error) { dial.stop() },
+				TLSHandshakeStart:    handshake.start,
+				TLSHandshakeDone:     func(tls.ConnectionState, error) { handshake.stop() },
+				WroteRequest:         func(httptrace.WroteRequestInfo) { header.start() },
+				GotFirstResponseByte: header.stop,
+			})
+
+			resp, err := next(req.WithContext(ctx))
+			stopAll()
+			if err != nil {
+				cancel(nil)
+				if timeoutErr, ok := timeoutCause(ctx); ok {
+					return nil, timeoutErr
+				}
+				return nil, err
+			}
+			resp.Body = &timeoutBody{
+				ReadCloser: resp.Body,
+				ctx:        ctx,
+				idle:       body,
+				done:       func() { cancel(nil) },
+			}
+			return resp, nil
+		}
+	}
+}
+
+// timeoutBody 响应体读取期间保持 ctx 有效，读取出错时返回对应的超时错误
+type timeoutBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	idle *phaseTimer // 每次 Read 的空闲超时，可为 nil
+	once sync.Once
+	done func()
+}
+
+// Read 实现 io.Reader 接口
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	if b.idle != nil {
+		b.idle.start()
+		defer b.idle.stop()
+	}
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if timeoutErr, ok := timeoutCause(b.ctx); ok {
+			return n, timeoutErr
+		}
+	}
+	return n, err
+}
+
+// Close 关闭响应体并释放 ctx
+func (b *timeoutBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
@@ -0,0 +1,182 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// ==================== 分阶段超时测试 ====================
+
+func TestClient_PhaseTimeouts(t *testing.T) {
+	// 只接受 TCP 连接、从不响应的服务端，TLS 握手会一直等待
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	slowServer := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	defer slowServer.Close()
+
+	// 连接阶段一直阻塞的拨号器
+	hangingDial := &http.Transport{
+		DialContext: (&net.Dialer{
+			ControlContext: func(ctx context.Context, network, address string, c syscall.RawConn) error {
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+				return errors.New("dial aborted")
+			},
+		}).DialContext,
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr error
+	}{
+		{
+			name:    "连接超时",
+			config:  Config{BaseURL: slowServer.URL, RoundTripper: hangingDial, DialTimeout: 50 * time.Millisecond},
+			wantErr: ErrDialTimeout,
+		},
+		{
+			name:    "TLS握手超时",
+			config:  Config{BaseURL: "https://" + listener.Addr().String(), TLSHandshakeTimeout: 50 * time.Millisecond},
+			wantErr: ErrTLSHandshakeTimeout,
+		},
+		{
+			name:    "响应头超时",
+			config:  Config{BaseURL: slowServer.URL, ResponseHeaderTimeout: 50 * time.Millisecond},
+			wantErr: ErrResponseHeaderTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.MaxRetries = -1
+			client := NewClient(tt.config)
+
+			start := time.Now()
+			_, err := client.Get(context.Background(), "/test", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("期望 %v，实际 %v", tt.wantErr, err)
+			}
+			var timeoutErr interface{ Timeout() bool }
+			if !errors.As(err, &timeoutErr) || !timeoutErr.Timeout() {
+				t.Errorf("超时错误的 Timeout() 应为 true")
+			}
+			if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+				t.Errorf("耗时 %v，超时未及时生效", elapsed)
+			}
+		})
+	}
+}
+
+func TestClient_BodyReadTimeout(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 持续输出数据，每次间隔 20ms，共 200ms
+		for i := 0; i < 10; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+		if r.URL.Path == "/stall" {
+			time.Sleep(300 * time.Millisecond)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:         server.URL,
+		Timeout:         -1,
+		BodyReadTimeout: 100 * time.Millisecond,
+	})
+
+	// 数据持续到达，总耗时超过空闲超时也不会中断
+	resp, err := client.Get(context.Background(), "/stream", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || len(body) != 50 {
+		t.Fatalf("读取 %d 字节，错误 %v，期望完整读取 50 字节", len(body), err)
+	}
+
+	// 数据中断超过空闲超时
+	resp, err = client.Get(context.Background(), "/stall", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrBodyReadTimeout) {
+		t.Errorf("期望 ErrBodyReadTimeout，实际 %v", err)
+	}
+}
+
+// ==================== 总超时测试 ====================
+
+func TestClient_TotalTimeout(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:      server.URL,
+		MaxRetries:   10,
+		RetryDelay:   50 * time.Millisecond,
+		TotalTimeout: 120 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := client.Get(context.Background(), "/test", nil)
+	if !errors.Is(err, ErrTotalTimeout) {
+		t.Fatalf("期望 ErrTotalTimeout，实际 %v", err)
+	}
+	// 总超时覆盖所有重试，而不是每次尝试单独计时
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("耗时 %v，总超时未覆盖重试", elapsed)
+	}
+}
+
+func TestClient_TotalTimeoutCallerCancel(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1, TotalTimeout: time.Second})
+
+	// 调用方自己的 ctx 超时不应报告为 ErrTotalTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Get(ctx, "/test", nil)
+	if err == nil || errors.Is(err, ErrTotalTimeout) {
+		t.Errorf("期望调用方 ctx 的错误，实际 %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望 context.DeadlineExceeded，实际 %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package httpx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// W3C Trace Context 使用的 header
+const (
+	TraceparentHeader = "Traceparent"
+	TracestateHeader  = "Tracestate"
+)
+
+// errInvalidTraceparent traceparent 格式错误
+var errInvalidTraceparent = errors.New("httpx: 无效的 traceparent")
+
+// TraceID 16 字节的链路 ID
+type TraceID [16]byte
+
+// SpanID 8 字节的 span ID
+type SpanID [8]byte
+
+// SpanContext 需要跨进程传播的 span 信息，对应 W3C Trace Context
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// TraceFlags 最低位为采样标记
+	TraceFlags byte
+	// TraceState 原样传播的 tracestate
+	TraceState string
+}
+
+// IsValid 判断 TraceID 和 SpanID 是否都不为零
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent 返回 W3C traceparent header 的值
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), sc.TraceFlags)
+}
+
+// ParseTraceparent 解析 traceparent 和 tracestate header，可用于服务端提取上游的链路信息
+func ParseTraceparent(traceparent, tracestate string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	// 未来版本可能追加字段，只要求前四段格式正确
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, errInvalidTraceparent
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, errInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, errInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, errInvalidTraceparent
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil || !sc.IsValid() {
+		return sc, errInvalidTraceparent
+	}
+	sc.TraceFlags = byte(flags)
+	sc.TraceState = tracestate
+	return sc, nil
+}
+
+// Attribute span 属性
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr 创建 span 属性
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer 链路追踪接口，可适配 OpenTelemetry 等实现
+type Tracer interface {
+	// Start 创建 span，父 span 通过 SpanContextFromContext(ctx) 获取
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span 一次操作的追踪记录
+type Span interface {
+	// SpanContext 返回用于传播的 span 信息
+	SpanContext() SpanContext
+	SetAttributes(attrs ...Attribute)
+	AddEvent(name string, attrs ...Attribute)
+	// SetError 标记 span 失败，对应 OpenTelemetry 的 Error 状态
+	SetError(description string)
+	End()
+}
+
+// spanContextKey 上下文中保存 SpanContext 的 key
+type spanContextKey struct{}
+
+// spanKey 上下文中保存当前 Span 的 key
+type spanKey struct{}
+
+// ContextWithSpanContext 在上下文中保存 SpanContext，之后的请求以它为父 span
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext 返回上下文中的 SpanContext，不存在时返回零值
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// SpanFromContext 返回客户端为当前请求创建的 Span，未开启追踪时返回 nil
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+// ==================== 追踪中间件 ====================
+
+// traceRequest 追踪中间件，位于重试之外，每个逻辑请求一个 span，并注入 W3C header
+func traceRequest(tracer Tracer) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			name := req.Method
+			attrs := []Attribute{
+				Attr("http.request.method", req.Method),
+				Attr("server.address", req.URL.Hostname()),
+				Attr("url.full", req.URL.Redacted()),
+			}
+			// 没有路由模板时 span 名称只包含方法，与 OpenTelemetry 语义约定一致
+			if route := routeOf(req); route != "" {
+				name += " " + route
+				attrs = append(attrs, Attr("http.route", route))
+			}
+			ctx, span := tracer.Start(req.Context(), name, attrs...)
+			defer span.End()
+
+			sc := span.SpanContext()
+			ctx = ContextWithSpanContext(ctx, sc)
+			ctx = context.WithValue(ctx, spanKey{}, span)
+			req = req.WithContext(ctx)
+			if sc.IsValid() {
+				req.Header.Set(TraceparentHeader, sc.Traceparent())
+				if sc.TraceState != "" {
+					req.Header.Set(TracestateHeader, sc.TraceState)
+				} else {
+					req.Header.Del(TracestateHeader)
+				}
+			}
+
+			resp, err := next(req)
+			if err != nil {
+				span.SetAttributes(Attr("error.type", fmt.Sprintf("%T", err)))
+				span.SetError(err.Error())
+				return nil, err
+			}
+			span.SetAttributes(Attr("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetAttributes(Attr("error.type", strconv.Itoa(resp.StatusCode)))
+				span.SetError(resp.Status)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// traceAttempt 位于重试之内，把每次尝试记录为 span 的事件
+func traceAttempt(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		span := SpanFromContext(req.Context())
+		if span == nil {
+			return next(req)
+		}
+
+		resp, err := next(req)
+		attrs := []Attribute{Attr("attempt", AttemptFromContext(req.Context()))}
+		if err != nil {
+			attrs = append(attrs, Attr("error", err.Error()))
+		} else {
+			attrs = append(attrs, Attr("http.response.status_code", resp.StatusCode))
+		}
+		span.AddEvent("http.attempt", attrs...)
+		return resp, err
+	}
+}
+
+// ==================== 内存追踪 ====================
+
+// SpanEvent span 上的事件
+type SpanEvent struct {
+	Name       string
+	Attributes []Attribute
+}
+
+// RecordedSpan 内存追踪记录的 span
+type RecordedSpan struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext
+	Attributes []Attribute
+	Events     []SpanEvent
+	// Error SetError 的描述，为空表示未失败
+	Error string
+}
+
+// Attribute 返回属性值，不存在时返回 nil
+func (s *RecordedSpan) Attribute(key string) any {
+	for i := len(s.Attributes) - 1; i >= 0; i-- {
+		if s.Attributes[i].Key == key {
+			return s.Attributes[i].Value
+		}
+	}
+	return nil
+}
+
+// MemoryTracer 在内存中记录 span 的 Tracer，不需要采集端，适合测试
+type MemoryTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewMemoryTracer 创建内存追踪
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+// Start 实现 Tracer 接口；上下文中有父 span 时沿用其 TraceID、TraceFlags 和 TraceState
+func (t *MemoryTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{TraceFlags: 1}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.TraceFlags = parent.TraceFlags
+		sc.TraceState = parent.TraceState
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+
+	span := &memorySpan{
+		tracer: t,
+		record: &RecordedSpan{
+			Name:       name,
+			Context:    sc,
+			Parent:     parent,
+			Attributes: append([]Attribute(nil), attrs...),
+		},
+	}
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+// Spans 返回已结束的 span，按结束顺序排列
+func (t *MemoryTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// memorySpan MemoryTracer 创建的 span
+type memorySpan struct {
+	tracer *MemoryTracer
+	mu     sync.Mutex
+	record *RecordedSpan
+	ended  bool
+}
+
+// SpanContext 实现 Span 接口
+func (s *memorySpan) SpanContext() SpanContext {
+	return s.record.Context
+}
+
+// SetAttributes 实现 Span 接口
+func (s *memorySpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record.Attributes = append(s.record.Attributes, attrs...)
+}
+
+// AddEvent 实现 Span 接口
+func (s *memorySpan) AddEvent(name string, attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record.Events = append(s.record.Events, SpanEvent{Name: name, Attributes: attrs})
+}
+
+// SetError 实现 Span 接口
+func (s *memorySpan) SetError(description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record.Error = description
+}
+
+// End 实现 Span 接口，重复调用无效
+func (s *memorySpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, s.record)
+	s.tracer.mu.Unlock()
+}
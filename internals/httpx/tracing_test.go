@@ -0,0 +1,155 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// ==================== traceparent 解析测试 ====================
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantErr     bool
+	}{
+		{name: "合法", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "未来版本追加字段", traceparent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{name: "版本00不允许追加字段", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+		{name: "版本ff", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "全零TraceID", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "长度错误", traceparent: "00-4bf92f3577b34da6-00f067aa0ba902b7-01", wantErr: true},
+		{name: "非十六进制", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.traceparent, "k=v")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceparent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (sc.TraceFlags != 1 || sc.TraceState != "k=v") {
+				t.Errorf("TraceFlags = %d, TraceState = %q", sc.TraceFlags, sc.TraceState)
+			}
+		})
+	}
+
+	sc, _ := ParseTraceparent(tests[0].traceparent, "")
+	if got := sc.Traceparent(); got != tests[0].traceparent {
+		t.Errorf("Traceparent() = %q，期望 %q", got, tests[0].traceparent)
+	}
+}
+
+// ==================== 客户端追踪测试 ====================
+
+func TestClient_Tracing(t *testing.T) {
+	var attempts atomic.Int32
+	var traceparents []string
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if r.Header.Get("tracestate") != "vendor=1" {
+			t.Errorf("tracestate = %q", r.Header.Get("tracestate"))
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	tracer := NewMemoryTracer()
+	client := NewClient(Config{BaseURL: server.URL, RetryDelay: 1, Tracer: tracer})
+
+	// 模拟服务端收到的上游链路
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=1")
+	ctx := ContextWithSpanContext(context.Background(), parent)
+
+	resp, err := client.Do(ctx, http.MethodGet, "/users/{id}", nil, WithPathParam("id", 7))
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := tracer.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("期望 1 个 span，实际 %d 个", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name != "GET /users/{id}" {
+		t.Errorf("span 名称 = %q", span.Name)
+	}
+	if span.Context.TraceID != parent.TraceID || span.Parent.SpanID != parent.SpanID {
+		t.Errorf("span 未延续上游链路")
+	}
+	if got := span.Attribute("http.response.status_code"); got != http.StatusOK {
+		t.Errorf("http.response.status_code = %v", got)
+	}
+	if span.Error != "" {
+		t.Errorf("成功的请求不应标记失败: %q", span.Error)
+	}
+
+	// 每次尝试一个事件，重试共用同一个 span
+	if len(span.Events) != 3 {
+		t.Fatalf("期望 3 个尝试事件，实际 %d 个", len(span.Events))
+	}
+	for i, event := range span.Events {
+		if event.Name != "http.attempt" || event.Attributes[0].Value != i {
+			t.Errorf("第 %d 个事件 = %+v", i, event)
+		}
+	}
+	want := span.Context.Traceparent()
+	for i, got := range traceparents {
+		if got != want {
+			t.Errorf("第 %d 次请求 traceparent = %q，期望 %q", i, got, want)
+		}
+	}
+}
+
+func TestClient_TracingError(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseURL   string
+		wantError string
+	}{
+		{name: "传输错误", baseURL: "http://127.0.0.1:0", wantError: "connect"},
+		{name: "4xx响应", wantError: "404"},
+	}
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.baseURL == "" {
+				tt.baseURL = server.URL
+			}
+			tracer := NewMemoryTracer()
+			client := NewClient(Config{BaseURL: tt.baseURL, MaxRetries: -1, Tracer: tracer})
+
+			resp, err := client.Get(context.Background(), "/test", nil)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			spans := tracer.Spans()
+			if len(spans) != 1 || !strings.Contains(spans[0].Error, tt.wantError) {
+				t.Fatalf("期望 span 标记失败并包含 %q，实际 %+v", tt.wantError, spans)
+			}
+			// 没有路由模板时 span 名称不包含原始路径
+			if spans[0].Name != http.MethodGet {
+				t.Errorf("span 名称 = %q，期望 %q", spans[0].Name, http.MethodGet)
+			}
+			// 没有上游链路时生成新的 TraceID
+			if spans[0].Parent.IsValid() || !spans[0].Context.IsValid() {
+				t.Errorf("根 span 的 SpanContext 不正确")
+			}
+		})
+	}
+}
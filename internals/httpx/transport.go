@@ -0,0 +1,176 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig 底层连接池、TLS 和代理配置
+type TransportConfig struct {
+	// MaxIdleConns 所有 host 的空闲连接总数上限，默认 100
+	MaxIdleConns int
+	// MaxIdleConnsPerHost 每个 host 保留的空闲连接数，默认 10
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 每个 host 的最大连接数，0 表示不限制
+	MaxConnsPerHost int
+	// IdleConnTimeout 空闲连接的保留时间，默认 90 秒
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 为 true 时只使用 HTTP/1.1
+	DisableHTTP2 bool
+
+	// ProxyURL 代理地址，支持 http、https、socks5，为空时读取 HTTP_PROXY 等环境变量
+	ProxyURL string
+	// DisableProxy 为 true 时不使用任何代理，包括环境变量
+	DisableProxy bool
+
+	// RootCAs 校验服务端证书的根证书，为 nil 时使用系统根证书
+	RootCAs *x509.CertPool
+	// RootCAFiles PEM 格式的根证书文件，追加到 RootCAs 或系统根证书
+	RootCAFiles []string
+	// Certificates 双向 TLS 的客户端证书
+	Certificates []tls.Certificate
+	// CertFile、KeyFile PEM 格式的客户端证书和私钥文件，必须同时设置
+	CertFile string
+	KeyFile  string
+	// ServerName 校验证书时使用的服务端名称，默认取请求的 host
+	ServerName string
+	// InsecureSkipVerify 跳过服务端证书校验，只能用于测试环境
+	InsecureSkipVerify bool
+}
+
+// validateTransport 校验传输层配置
+func (c *Config) validateTransport() error {
+	if c.Transport != nil && c.RoundTripper != nil {
+		return errors.New("httpx: Transport 和 RoundTripper 不能同时设置")
+	}
+	if c.Transport == nil {
+		return nil
+	}
+
+	t := c.Transport
+	switch {
+	case t.MaxIdleConns < 0, t.MaxIdleConnsPerHost < 0, t.MaxConnsPerHost < 0:
+		return errors.New("httpx: 连接数上限不能为负数")
+	case t.IdleConnTimeout < 0:
+		return errors.New("httpx: IdleConnTimeout 不能为负数")
+	case (t.CertFile == "") != (t.KeyFile == ""):
+		return errors.New("httpx: CertFile 和 KeyFile 必须同时设置")
+	case t.ProxyURL != "" && t.DisableProxy:
+		return errors.New("httpx: ProxyURL 和 DisableProxy 不能同时设置")
+	}
+	if t.ProxyURL != "" {
+		if _, err := parseProxyURL(t.ProxyURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProxyURL 解析并校验代理地址
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: 无效的代理地址 %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("httpx: 不支持的代理协议 %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("httpx: 代理地址 %q 缺少 host", raw)
+	}
+	return u, nil
+}
+
+// newTransport 根据配置创建 http.Transport，配置需已通过校验
+func newTransport(config TransportConfig) (*http.Transport, error) {
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = 90 * time.Second
+	}
+
+	// 以默认 Transport 为基础，保留拨号超时、TLS 握手超时等默认值
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = config.MaxIdleConns
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = config.IdleConnTimeout
+	transport.ForceAttemptHTTP2 = !config.DisableHTTP2
+	if config.DisableHTTP2 {
+		// 非 nil 的空 map 会禁用 HTTP/2
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	switch {
+	case config.DisableProxy:
+		transport.Proxy = nil
+	case config.ProxyURL != "":
+		proxy, err := parseProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// tlsConfig 根据配置创建 tls.Config
+func (config TransportConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            config.RootCAs,
+		Certificates:       append([]tls.Certificate(nil), config.Certificates...),
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if len(config.RootCAFiles) > 0 {
+		pool := config.RootCAs
+		if pool == nil {
+			var err error
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		} else {
+			// 不修改调用方传入的证书池
+			pool = pool.Clone()
+		}
+		for _, file := range config.RootCAFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("httpx: 读取根证书失败: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("httpx: 根证书 %s 中没有有效的 PEM 证书", file)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("httpx: 加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,277 @@
+package httpx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// writeClientCert 生成自签名的客户端证书，写入临时目录并返回证书和文件路径
+func writeClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "httpx-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return cert, certFile, keyFile
+}
+
+// writeServerCA 把 TLS 测试服务器的证书写入临时文件
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "ca.crt")
+	os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	return file
+}
+
+// getBody 发送 GET 请求并返回响应体
+func getBody(t *testing.T, client *Client, path string) string {
+	t.Helper()
+
+	resp, err := client.Get(context.Background(), path, nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, err := ParseRawResponse(resp)
+	if err != nil {
+		t.Fatalf("读取响应体失败: %v", err)
+	}
+	return string(body)
+}
+
+// ==================== 配置校验测试 ====================
+
+func TestNew_TransportValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name:    "Transport与RoundTripper同时设置",
+			config:  Config{Transport: &TransportConfig{}, RoundTripper: http.DefaultTransport},
+			wantErr: "不能同时设置",
+		},
+		{
+			name:    "负数连接数",
+			config:  Config{Transport: &TransportConfig{MaxIdleConnsPerHost: -1}},
+			wantErr: "负数",
+		},
+		{
+			name:    "只设置证书",
+			config:  Config{Transport: &TransportConfig{CertFile: "a.crt"}},
+			wantErr: "必须同时设置",
+		},
+		{
+			name:    "不支持的代理协议",
+			config:  Config{Transport: &TransportConfig{ProxyURL: "ftp://proxy:21"}},
+			wantErr: "不支持的代理协议",
+		},
+		{
+			name:    "根证书文件不存在",
+			config:  Config{Transport: &TransportConfig{RootCAFiles: []string{"/nonexistent/ca.pem"}}},
+			wantErr: "读取根证书失败",
+		},
+		{
+			name:   "合法配置",
+			config: Config{Transport: &TransportConfig{MaxIdleConnsPerHost: 32, ProxyURL: "socks5://127.0.0.1:1080"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("New() 返回错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v，期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient_PanicsOnInvalidConfig(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("无效配置期望 panic")
+		}
+	}()
+	NewClient(Config{Transport: &TransportConfig{IdleConnTimeout: -1}})
+}
+
+// ==================== TLS 测试 ====================
+
+func TestClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	tests := []struct {
+		name      string
+		transport *TransportConfig
+		wantErr   bool
+	}{
+		{name: "系统根证书校验失败", transport: &TransportConfig{}, wantErr: true},
+		{name: "自定义根证书", transport: &TransportConfig{RootCAs: pool}},
+		{name: "根证书文件", transport: &TransportConfig{RootCAFiles: []string{writeServerCA(t, server)}}},
+		{name: "跳过校验", transport: &TransportConfig{InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1, Transport: tt.transport})
+			resp, err := client.Get(context.Background(), "/test", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	clientCert, certFile, keyFile := writeClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCAs := x509.NewCertPool()
+	serverCAs.AddCert(server.Certificate())
+
+	// 未提供客户端证书时握手失败
+	client := NewClient(Config{BaseURL: server.URL, MaxRetries: -1, Transport: &TransportConfig{RootCAs: serverCAs}})
+	if resp, err := client.Get(context.Background(), "/", nil); err == nil {
+		resp.Body.Close()
+		t.Fatal("未提供客户端证书期望失败")
+	}
+
+	client = NewClient(Config{
+		BaseURL: server.URL,
+		Transport: &TransportConfig{
+			RootCAs:  serverCAs,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		},
+	})
+	if body := getBody(t, client, "/"); body != "httpx-client" {
+		t.Errorf("服务端看到的客户端证书 = %q", body)
+	}
+}
+
+func TestClient_DisableHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, disable := range []bool{false, true} {
+		client := NewClient(Config{
+			BaseURL:   server.URL,
+			Transport: &TransportConfig{InsecureSkipVerify: true, DisableHTTP2: disable},
+		})
+		resp, err := client.Get(context.Background(), "/", nil)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+
+		if wantMajor := map[bool]int{false: 2, true: 1}[disable]; resp.ProtoMajor != wantMajor {
+			t.Errorf("DisableHTTP2=%v 时协议 %s，期望 HTTP/%d", disable, resp.Proto, wantMajor)
+		}
+	}
+}
+
+// ==================== 代理和自定义传输测试 ====================
+
+func TestClient_Proxy(t *testing.T) {
+	proxy := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// 经过代理的请求使用绝对地址
+		w.Write([]byte("proxied " + r.URL.String()))
+	})
+	defer proxy.Close()
+
+	client := NewClient(Config{
+		BaseURL:   "http://upstream.internal",
+		Transport: &TransportConfig{ProxyURL: proxy.URL},
+	})
+	if body := getBody(t, client, "/users"); body != "proxied http://upstream.internal/users" {
+		t.Errorf("响应 = %q", body)
+	}
+}
+
+func TestClient_CustomRoundTripper(t *testing.T) {
+	var called bool
+	client := NewClient(Config{
+		BaseURL: "http://example.invalid",
+		RoundTripper: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			called = true
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+		}),
+	})
+
+	resp, err := client.Get(context.Background(), "/", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if !called {
+		t.Error("自定义 RoundTripper 未被调用")
+	}
+}
@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// expandPath 展开路径模板，把 {name} 替换为转义后的参数值
+// 例如 /users/{id}/orders 配合 id=42 得到 /users/42/orders
+func expandPath(template string, params map[string]string) (string, error) {
+	if !strings.Contains(template, "{") {
+		return template, nil
+	}
+
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("httpx: 路径模板 %q 缺少 }", template)
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("httpx: 路径模板 %q 缺少参数 %q", template, name)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(escapePathSegment(value))
+		rest = rest[end+1:]
+	}
+}
+
+// escapePathSegment 转义单个路径段
+// "." 和 ".." 也需要转义，否则拼接时会被当作相对路径解析
+func escapePathSegment(value string) string {
+	if value == "." || value == ".." {
+		return strings.ReplaceAll(value, ".", "%2E")
+	}
+	return url.PathEscape(value)
+}
+
+// joinURL 拼接基础地址和请求路径
+// 自动处理重复或缺失的斜杠，并合并两者的查询参数；path 为绝对地址时直接使用
+func joinURL(base, path string) (string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if ref.IsAbs() || base == "" {
+		return ref.String(), nil
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	if ref.Path != "" {
+		u = u.JoinPath(ref.EscapedPath())
+	}
+	u.RawQuery = joinQuery(u.RawQuery, ref.RawQuery)
+	u.Fragment = ref.Fragment
+	return u.String(), nil
+}
+
+// joinQuery 拼接两个已编码的查询串
+func joinQuery(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "&" + b
+	}
+}
@@ -0,0 +1,137 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// ==================== 路径模板测试 ====================
+
+func TestExpandPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{name: "无模板", template: "/users", want: "/users"},
+		{name: "单个参数", template: "/users/{id}", params: map[string]string{"id": "42"}, want: "/users/42"},
+		{
+			name:     "多个参数",
+			template: "/users/{id}/orders/{orderID}",
+			params:   map[string]string{"id": "1", "orderID": "a-2"},
+			want:     "/users/1/orders/a-2",
+		},
+		{name: "转义斜杠", template: "/files/{name}", params: map[string]string{"name": "a/b"}, want: "/files/a%2Fb"},
+		{name: "转义空格和问号", template: "/q/{k}", params: map[string]string{"k": "a b?"}, want: "/q/a%20b%3F"},
+		{name: "转义点段", template: "/users/{id}", params: map[string]string{"id": ".."}, want: "/users/%2E%2E"},
+		{name: "缺少参数", template: "/users/{id}", wantErr: true},
+		{name: "未闭合", template: "/users/{id", params: map[string]string{"id": "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPath(tt.template, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expandPath() = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== URL 拼接测试 ====================
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		path string
+		want string
+	}{
+		{name: "普通拼接", base: "http://example.com", path: "/users", want: "http://example.com/users"},
+		{name: "重复斜杠", base: "http://example.com/api/", path: "/users", want: "http://example.com/api/users"},
+		{name: "缺少斜杠", base: "http://example.com/api", path: "users", want: "http://example.com/api/users"},
+		{name: "保留末尾斜杠", base: "http://example.com", path: "/users/", want: "http://example.com/users/"},
+		{name: "空路径", base: "http://example.com/api/", path: "", want: "http://example.com/api/"},
+		{name: "路径带查询", base: "http://example.com", path: "/search?q=go&page=2", want: "http://example.com/search?q=go&page=2"},
+		{name: "合并基础地址查询", base: "http://example.com?key=k", path: "/search?q=go", want: "http://example.com/search?key=k&q=go"},
+		{name: "保留已转义段", base: "http://example.com", path: "/files/a%2Fb", want: "http://example.com/files/a%2Fb"},
+		{name: "绝对地址", base: "http://example.com", path: "https://other.com/x", want: "https://other.com/x"},
+		{name: "无基础地址", base: "", path: "http://example.com/x", want: "http://example.com/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinURL(tt.base, tt.path)
+			if err != nil {
+				t.Fatalf("joinURL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("joinURL() = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== Do 测试 ====================
+
+func TestClient_Do(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.EscapedPath(); got != "/api/users/a%2Fb/orders" {
+			t.Errorf("路径 = %s", got)
+		}
+		want := url.Values{"status": {"paid", "new"}, "q": {"x&y"}}
+		if got := r.URL.Query(); got.Encode() != want.Encode() {
+			t.Errorf("查询参数 = %v，期望 %v", got, want)
+		}
+		if got := r.Header.Get("X-Tenant"); got != "t1" {
+			t.Errorf("X-Tenant = %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL + "/api/"})
+	resp, err := client.Do(context.Background(), http.MethodGet, "/users/{id}/orders", nil,
+		WithPathParam("id", "a/b"),
+		WithQuery("q", "x&y"),
+		WithQueryValues(url.Values{"status": {"paid", "new"}}),
+		WithHeader("X-Tenant", "t1"),
+	)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClient_DoMissingPathParam(t *testing.T) {
+	client := NewClient(Config{BaseURL: "http://example.com"})
+	if _, err := client.Do(context.Background(), http.MethodGet, "/users/{id}", nil); err == nil {
+		t.Error("缺少路径参数时期望返回错误")
+	}
+}
+
+func TestGetJSON_PathParams(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/7" || r.URL.Query().Get("fields") != "name" {
+			t.Errorf("URL = %s", r.URL)
+		}
+		w.Write([]byte(`{"id":7,"name":"dave"}`))
+	})
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	got, err := GetJSON[user](context.Background(), client, "/users/{id}", WithPathParam("id", 7), WithQuery("fields", "name"))
+	if err != nil {
+		t.Fatalf("GetJSON 失败: %v", err)
+	}
+	if got != (user{ID: 7, Name: "dave"}) {
+		t.Errorf("GetJSON() = %+v", got)
+	}
+}